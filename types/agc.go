@@ -0,0 +1,49 @@
+// Automatic gain control, to even out loudness over a stream of samples.
+package types
+
+import (
+	"math"
+)
+
+// AGC holds the running state of an automatic gain control.
+type AGC struct {
+	targetRMS float64
+	maxGain   float64
+	smoothing float64
+
+	meanSquare float64
+	gain       float64
+}
+
+// NewAGC creates an automatic gain control that pushes the running RMS of its input
+// towards targetRMS, never amplifying by more than maxGain.
+// smoothing is in [0, 1), with values closer to 1 reacting more slowly.
+func NewAGC(targetRMS float64, maxGain float64, smoothing float64) *AGC {
+	return &AGC{
+		targetRMS: targetRMS,
+		maxGain:   maxGain,
+		smoothing: smoothing,
+		gain:      1.0,
+	}
+}
+
+// Process updates the running loudness with a new sample, and returns it with gain applied.
+func (a *AGC) Process(sample float64) float64 {
+	a.meanSquare = a.smoothing*a.meanSquare + (1.0-a.smoothing)*sample*sample
+
+	desired := a.maxGain
+	if rms := math.Sqrt(a.meanSquare); rms > 0 {
+		desired = math.Min(a.targetRMS/rms, a.maxGain)
+	}
+	a.gain = a.smoothing*a.gain + (1.0-a.smoothing)*desired
+	return sample * a.gain
+}
+
+// ProcessBuffer runs the gain control over the float64 values in a buffer, least recent first.
+func (a *AGC) ProcessBuffer(b *TypedBuffer) []float64 {
+	values := b.floatValues()
+	for i, v := range values {
+		values[i] = a.Process(v)
+	}
+	return values
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// rms returns the root mean square of some values.
+func rms(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+func TestAGCConvergesToTarget(t *testing.T) {
+	segment := 20000
+	amplitudes := []float64{0.05, 0.8}
+	target := 0.3
+
+	b := NewTypedBuffer(segment * len(amplitudes))
+	for _, amplitude := range amplitudes {
+		for i := 0; i < segment; i++ {
+			b.Push(amplitude * math.Sin(2*math.Pi*440*float64(i)/44100))
+		}
+	}
+
+	output := NewAGC(target, 20, 0.999).ProcessBuffer(b)
+	for s := range amplitudes {
+		// Measure the second half of each segment, once the gain has settled.
+		tail := output[s*segment+segment/2 : (s+1)*segment]
+		if actual := rms(tail); math.Abs(actual-target) > 0.1*target {
+			t.Errorf("Segment %d: expected RMS near %f, got %f", s, target, actual)
+		}
+	}
+}
+
+func TestAGCLimitsGain(t *testing.T) {
+	agc := NewAGC(1.0, 2.0, 0.9)
+	for i := 0; i < 1000; i++ {
+		if out := agc.Process(0.01); out > 0.02+1e-12 {
+			t.Fatalf("Expected gain to be capped at 2, got output %f", out)
+		}
+	}
+}
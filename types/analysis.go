@@ -0,0 +1,147 @@
+// Measurements of the float64 values held in a typed buffer.
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ParabolicPeak finds the largest float64 value in the buffer, and fits a parabola through it
+// and its neighbours to estimate where the true peak lies between samples. The position is a
+// fractional index, least recent first. ok is false if the buffer is empty, or the largest
+// value is at either end so has no neighbour to fit against.
+func (b *TypedBuffer) ParabolicPeak() (position float64, value float64, ok bool) {
+	values := b.floatValues()
+	if len(values) == 0 {
+		return 0, 0, false
+	}
+
+	at := 0
+	for i, v := range values {
+		if v > values[at] {
+			at = i
+		}
+	}
+	if at == 0 || at == len(values)-1 {
+		return float64(at), values[at], false
+	}
+
+	left, centre, right := values[at-1], values[at], values[at+1]
+	curvature := left - 2*centre + right
+	if curvature == 0 {
+		// Flat top, no better estimate than the sample itself.
+		return float64(at), centre, true
+	}
+	offset := 0.5 * (left - right) / curvature
+	return float64(at) + offset, centre - 0.25*(left-right)*offset, true
+}
+
+// ClipRuns returns the [start, end) index ranges of runs of consecutive float64 values
+// at or above the ceiling that are at least minLength long, least recent first.
+// Short runs are ignored, so that a single loud sample isn't mistaken for clipping.
+func (b *TypedBuffer) ClipRuns(ceiling float64, minLength int) [][2]int {
+	values := b.floatValues()
+	runs := [][2]int{}
+	start := -1
+	for i := 0; i <= len(values); i++ {
+		if i < len(values) && values[i] >= ceiling {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLength {
+			runs = append(runs, [2]int{start, i})
+		}
+		start = -1
+	}
+	return runs
+}
+
+// DistinctCount returns how many different values are in the buffer, so a full buffer with a
+// count of 1 holds a flatlined input. Values that can't be compared with == (like slices)
+// are each counted as distinct.
+func (b *TypedBuffer) DistinctCount() int {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+
+	seen := make(map[interface{}]bool)
+	uncomparable := 0
+	b.eachLocked(func(i int, value interface{}) {
+		if !isComparable(value) {
+			uncomparable++
+			return
+		}
+		seen[value] = true
+	})
+	return len(seen) + uncomparable
+}
+
+// DecayWeightedMean returns the mean of the float64 values in the buffer, weighted so that
+// each value counts half as much as one halfLifeSamples more recent. Zero is returned if empty.
+// A half life of zero or less is the limit of a tiny one, so gives the most recent value.
+func (b *TypedBuffer) DecayWeightedMean(halfLifeSamples float64) float64 {
+	values := b.floatValues()
+	if halfLifeSamples <= 0 {
+		if len(values) == 0 {
+			return 0.0
+		}
+		return values[len(values)-1]
+	}
+	sum, totalWeight := 0.0, 0.0
+	for i, v := range values {
+		age := float64(len(values) - 1 - i)
+		weight := math.Exp2(-age / halfLifeSamples)
+		sum += weight * v
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0.0
+	}
+	return sum / totalWeight
+}
+
+// SNR returns the ratio of the power of the float64 values in signal to those in noise, in dB.
+// Mean power is used, so the buffers don't need to be the same size, but neither can be empty
+// and the noise can't be silent.
+func SNR(signal *TypedBuffer, noise *TypedBuffer) (float64, error) {
+	signalValues, noiseValues := signal.floatValues(), noise.floatValues()
+	if len(signalValues) == 0 || len(noiseValues) == 0 {
+		return 0, ErrEmptyBuffer
+	}
+	noisePower := meanSquare(noiseValues)
+	if noisePower == 0 {
+		return 0, errors.New("SNR noise has zero power")
+	}
+	return 10 * math.Log10(meanSquare(signalValues)/noisePower), nil
+}
+
+// Autocorrelation returns r[0..maxLag] of the float64 values in the buffer, least recent first.
+// This is the biased estimator, with every lag divided by the number of values n rather than
+// the n - k products summed, which keeps the result positive semi-definite as Levinson-Durbin
+// needs. maxLag must be less than n.
+func (b *TypedBuffer) Autocorrelation(maxLag int) ([]float64, error) {
+	values := b.floatValues()
+	if maxLag < 0 || maxLag >= len(values) {
+		return nil, fmt.Errorf("%w: lag %d with %d values", ErrIndexOutOfRange, maxLag, len(values))
+	}
+	result := make([]float64, maxLag+1)
+	for k := range result {
+		sum := 0.0
+		for i := 0; i+k < len(values); i++ {
+			sum += values[i] * values[i+k]
+		}
+		result[k] = sum / float64(len(values))
+	}
+	return result, nil
+}
+
+// meanSquare returns the average of the squares of some values.
+func meanSquare(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v * v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParabolicPeak(t *testing.T) {
+	truePosition, trueValue := 4.3, 2.0
+	values := make([]float64, 10)
+	for i := range values {
+		d := float64(i) - truePosition
+		values[i] = trueValue - 0.5*d*d
+	}
+
+	position, value, ok := newFloatBuffer(values).ParabolicPeak()
+	if !ok {
+		t.Fatalf("Expected a peak to be found")
+	}
+	if math.Abs(position-truePosition) > 1e-9 || math.Abs(value-trueValue) > 1e-9 {
+		t.Errorf("Expected peak %f at %f, got %f at %f", trueValue, truePosition, value, position)
+	}
+	if position == math.Floor(position) {
+		t.Errorf("Expected the peak to lie between samples, got %f", position)
+	}
+}
+
+func TestParabolicPeakEdges(t *testing.T) {
+	if _, _, ok := NewTypedBuffer(4).ParabolicPeak(); ok {
+		t.Errorf("Expected no peak for an empty buffer")
+	}
+	if _, _, ok := newFloatBuffer([]float64{3, 2, 1}).ParabolicPeak(); ok {
+		t.Errorf("Expected no peak at the start")
+	}
+	if _, _, ok := newFloatBuffer([]float64{1, 2, 3}).ParabolicPeak(); ok {
+		t.Errorf("Expected no peak at the end")
+	}
+}
+
+func TestClipRuns(t *testing.T) {
+	values := make([]float64, 40)
+	values[3] = 1.0                   // Single loud sample.
+	values[10], values[11] = 1.0, 0.9 // Short spike.
+	for i := 20; i < 28; i++ {
+		values[i] = 1.0 // Sustained plateau.
+	}
+	values[38], values[39] = 1.0, 1.0 // Plateau running off the end.
+
+	runs := newFloatBuffer(values).ClipRuns(0.99, 2)
+	expected := [][2]int{{20, 28}, {38, 40}}
+	if len(runs) != len(expected) {
+		t.Fatalf("Expected runs %v, got %v", expected, runs)
+	}
+	for i := range expected {
+		if runs[i] != expected[i] {
+			t.Fatalf("Expected runs %v, got %v", expected, runs)
+		}
+	}
+
+	if runs := newFloatBuffer(values).ClipRuns(0.99, 3); len(runs) != 1 || runs[0] != [2]int{20, 28} {
+		t.Errorf("Expected only the long plateau, got %v", runs)
+	}
+}
+
+func TestDistinctCount(t *testing.T) {
+	if n := constantBuffer(0.3, 20).DistinctCount(); n != 1 {
+		t.Errorf("Expected 1 distinct value for a constant signal, got %d", n)
+	}
+
+	alternating := NewTypedBuffer(20)
+	for i := 0; i < 25; i++ {
+		alternating.Push(float64(i % 2))
+	}
+	if n := alternating.DistinctCount(); n != 2 {
+		t.Errorf("Expected 2 distinct values for an alternating signal, got %d", n)
+	}
+
+	varying := newFloatBuffer([]float64{1, 2, 3, 2, 1, 5})
+	if n := varying.DistinctCount(); n != 4 {
+		t.Errorf("Expected 4 distinct values, got %d", n)
+	}
+
+	mixed := NewTypedBuffer(5)
+	mixed.Push(1)
+	mixed.Push(1.0)
+	mixed.Push([]float64{1})
+	mixed.Push([]float64{1})
+	mixed.Push(nil)
+	if n := mixed.DistinctCount(); n != 5 {
+		t.Errorf("Expected 5 distinct values with uncomparable ones, got %d", n)
+	}
+}
+
+func TestDecayWeightedMean(t *testing.T) {
+	values := []float64{4, 8, 2, 6}
+	b := newFloatBuffer(values)
+
+	// Half life of 2: weights for ages 3, 2, 1, 0
+	weights := []float64{math.Pow(0.5, 1.5), 0.5, math.Pow(0.5, 0.5), 1}
+	sum, total := 0.0, 0.0
+	for i, v := range values {
+		sum += weights[i] * v
+		total += weights[i]
+	}
+	if actual := b.DecayWeightedMean(2); math.Abs(actual-sum/total) > 1e-12 {
+		t.Errorf("Expected %f, got %f", sum/total, actual)
+	}
+
+	if actual := b.DecayWeightedMean(0.01); math.Abs(actual-6) > 1e-6 {
+		t.Errorf("Expected the newest value to dominate, got %f", actual)
+	}
+	if actual := b.DecayWeightedMean(1e12); math.Abs(actual-5) > 1e-6 {
+		t.Errorf("Expected a plain mean for a huge half life, got %f", actual)
+	}
+	if actual := NewTypedBuffer(3).DecayWeightedMean(2); actual != 0 {
+		t.Errorf("Expected 0 for an empty buffer, got %f", actual)
+	}
+
+	for _, halfLife := range []float64{0, -3} {
+		if actual := b.DecayWeightedMean(halfLife); actual != 6 {
+			t.Errorf("Half life %f: expected the newest value, got %f", halfLife, actual)
+		}
+		if actual := NewTypedBuffer(3).DecayWeightedMean(halfLife); actual != 0 {
+			t.Errorf("Half life %f: expected 0 for an empty buffer, got %f", halfLife, actual)
+		}
+	}
+}
+
+func TestSNR(t *testing.T) {
+	signal := sineBuffer(1.0, 440, 44100, 44100)
+	noiseValues := make([]float64, 1000)
+	for i := range noiseValues {
+		// Square wave of amplitude 0.01, so power 1e-4 against the sine's 0.5
+		noiseValues[i] = 0.01 * math.Copysign(1, math.Sin(float64(i)))
+	}
+	noise := newFloatBuffer(noiseValues)
+
+	expected := 10 * math.Log10(0.5/1e-4)
+	actual, err := SNR(signal, noise)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if math.Abs(actual-expected) > 0.01 {
+		t.Errorf("Expected SNR %f dB, got %f", expected, actual)
+	}
+
+	if _, err := SNR(signal, constantBuffer(0, 10)); err == nil {
+		t.Errorf("Expected error for silent noise")
+	}
+	if _, err := SNR(NewTypedBuffer(4), noise); err == nil {
+		t.Errorf("Expected error for an empty signal")
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	actual, err := newFloatBuffer([]float64{1, 2, 3}).Autocorrelation(2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertClose(t, []float64{14.0 / 3, 8.0 / 3, 1}, actual, 1e-12)
+
+	// Alternating +/-1 has r[k] = (-1)^k (n - k) / n
+	n := 64
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = 1 - 2*float64(i%2)
+	}
+	actual, err = newFloatBuffer(values).Autocorrelation(10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := make([]float64, 11)
+	for k := range expected {
+		expected[k] = math.Pow(-1, float64(k)) * float64(n-k) / float64(n)
+	}
+	assertClose(t, expected, actual, 1e-12)
+}
+
+func TestAutocorrelationLagTooLarge(t *testing.T) {
+	b := newFloatBuffer([]float64{1, 2, 3})
+	if _, err := b.Autocorrelation(3); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := b.Autocorrelation(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for a negative lag, got %v", err)
+	}
+}
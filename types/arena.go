@@ -0,0 +1,68 @@
+// An arena for allocating many small typed buffers from one block of memory.
+package types
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Arena hands out buffers whose values are all stored in one slice, allocated up front,
+// rather than each buffer making its own. This suits many small buffers with the same
+// lifetime, such as one per voice of a synth.
+//
+// The storage is only reclaimed once the arena and every buffer from it are unreachable.
+// After Release, buffers from the arena must no longer be used.
+type Arena struct {
+	values []interface{}
+	used   int
+	lock   sync.Mutex
+}
+
+// NewArena creates an arena with room for buffers totalling totalCapacity values.
+func NewArena(totalCapacity int) *Arena {
+	if totalCapacity < 0 {
+		panic(fmt.Sprintf("NewArena capacity must not be negative, got %d", totalCapacity))
+	}
+	return &Arena{values: make([]interface{}, totalCapacity)}
+}
+
+// NewBuffer creates a new buffer of the given capacity, stored within the arena.
+// It panics if the arena doesn't have that much room left, or has been released.
+func (a *Arena) NewBuffer(capacity int) *TypedBuffer {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if capacity < 0 {
+		panic(fmt.Sprintf("Arena NewBuffer capacity must not be negative, got %d", capacity))
+	}
+	if a.values == nil {
+		panic("Arena NewBuffer called after Release")
+	}
+	if capacity > len(a.values)-a.used {
+		panic(fmt.Sprintf("Arena has room for %d more values, not %d", len(a.values)-a.used, capacity))
+	}
+	// Limit the capacity of the slice too, so nothing can ever reach into a neighbour.
+	values := a.values[a.used : a.used+capacity : a.used+capacity]
+	a.used += capacity
+	b := newTypedBuffer(values)
+	b.fromArena = true
+	return b
+}
+
+// Remaining returns how many more values the arena has room for.
+func (a *Arena) Remaining() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return len(a.values) - a.used
+}
+
+// Release clears every value held by buffers from the arena, so they can be garbage collected,
+// and stops any more buffers being created. The buffers themselves must not be used afterwards.
+func (a *Arena) Release() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i := range a.values {
+		a.values[i] = nil
+	}
+	a.values = nil
+	a.used = 0
+}
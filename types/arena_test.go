@@ -0,0 +1,67 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestArenaBuffersIndependent(t *testing.T) {
+	arena := NewArena(10)
+	buffers := []*TypedBuffer{arena.NewBuffer(3), arena.NewBuffer(2), arena.NewBuffer(4)}
+	if actual := arena.Remaining(); actual != 1 {
+		t.Fatalf("Expected 1 value left in the arena, got %d", actual)
+	}
+
+	// Wrapping each buffer a few times must never touch its neighbours.
+	for i := 0; i < 10; i++ {
+		for j, b := range buffers {
+			b.Push(float64(100*j + i))
+		}
+	}
+	assertValues(t, []float64{7, 8, 9}, buffers[0].Freeze().values)
+	assertValues(t, []float64{108, 109}, buffers[1].Freeze().values)
+	assertValues(t, []float64{206, 207, 208, 209}, buffers[2].Freeze().values)
+
+	buffers[1].Clear()
+	if !buffers[0].IsFull() || buffers[1].Size() != 0 || !buffers[2].IsFull() {
+		t.Errorf("Expected Clear to only affect its own buffer")
+	}
+}
+
+func TestArenaBufferBlocking(t *testing.T) {
+	b := NewArena(2).NewBuffer(2)
+	b.Push(1.0)
+	if b.IsStale(time.Hour) {
+		t.Errorf("Expected a fresh push not to be stale")
+	}
+	if value, err := b.PopBlocking(context.Background()); err != nil || value != 1.0 {
+		t.Errorf("Expected to pop 1, got %v, %v", value, err)
+	}
+}
+
+func TestArenaExhausted(t *testing.T) {
+	arena := NewArena(4)
+	arena.NewBuffer(3)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic allocating past the end of the arena")
+		}
+	}()
+	arena.NewBuffer(2)
+}
+
+func TestArenaRelease(t *testing.T) {
+	arena := NewArena(4)
+	arena.NewBuffer(2).Push(1.0)
+	arena.Release()
+	if actual := arena.Remaining(); actual != 0 {
+		t.Errorf("Expected no room after Release, got %d", actual)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic allocating after Release")
+		}
+	}()
+	arena.NewBuffer(1)
+}
@@ -140,3 +140,14 @@ func (b *Buffer) Each(cb func(int, float64)) {
 	}
 	b.lock.Unlock()
 }
+
+// oldest returns the least recent value, about to be replaced by the next Push,
+// or zero if the buffer isn't full yet.
+func (b *Buffer) oldest() float64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.size < b.capacity {
+		return 0.0
+	}
+	return b.values[b.at]
+}
@@ -0,0 +1,77 @@
+// A pool for recycling short-lived typed buffers.
+package types
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// BufferPool recycles TypedBuffers, keeping a separate pool per capacity.
+type BufferPool struct {
+	pools map[int]*sync.Pool
+	lock  sync.Mutex
+}
+
+// NewBufferPool creates a new, empty pool of buffers.
+func NewBufferPool() *BufferPool {
+	return &BufferPool{pools: make(map[int]*sync.Pool)}
+}
+
+// Get returns an empty buffer of the given capacity, reusing one if available.
+func (p *BufferPool) Get(capacity int) *TypedBuffer {
+	return p.poolFor(capacity).Get().(*TypedBuffer)
+}
+
+// Put resets the buffer to how NewTypedBuffer made it, and returns it to the pool for its capacity.
+// Buffers that can't be made equivalent to a new one are left alone and not pooled: strict
+// or unsafe buffers, those from an Arena, and those with FullEvents, OverrunEvents, a chunk sink
+// or a resize hook attached, as something else may still be watching them.
+func (p *BufferPool) Put(b *TypedBuffer) {
+	if b == nil || !b.resetForPool() {
+		return
+	}
+	p.poolFor(b.capacity).Put(b)
+}
+
+// resetForPool returns the buffer to its newly created state, or returns false if it can't be.
+func (b *TypedBuffer) resetForPool() bool {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.noLock || b.kind != reflect.Invalid || b.fromArena ||
+		b.fullEvents != nil || b.overrunEvents != nil || b.chunkSink != nil || b.onResize != nil {
+		return false
+	}
+
+	// Drop references to the old values, so the pool doesn't keep them alive.
+	for i := range b.values {
+		b.values[i] = nil
+	}
+	b.size, b.at, b.finished = 0, 0, false
+	b.skipInvalid = false
+	b.sinceFull = 0
+	b.lastPush, b.nowFunc = time.Time{}, time.Now
+	b.unread, b.lost = 0, 0
+	b.pushed, b.evicted = 0, 0
+	b.chunkSize, b.chunkPending = 0, 0
+	b.occupancy = nil
+	b.sampleRate = 0
+	b.weights = nil
+	return true
+}
+
+// poolFor returns the pool holding buffers of a given capacity, creating it if needed.
+func (p *BufferPool) poolFor(capacity int) *sync.Pool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pool, ok := p.pools[capacity]
+	if !ok {
+		pool = &sync.Pool{
+			New: func() interface{} {
+				return NewTypedBuffer(capacity)
+			},
+		}
+		p.pools[capacity] = pool
+	}
+	return pool
+}
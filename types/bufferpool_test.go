@@ -0,0 +1,86 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBufferPoolGetReturnsCleared(t *testing.T) {
+	p := NewBufferPool()
+	b := p.Get(4)
+	for i := 0; i < 3; i++ {
+		b.Push(float64(i))
+	}
+	p.Put(b)
+
+	b = p.Get(4)
+	if b.Size() != 0 {
+		t.Fatalf("Expected a cleared buffer, got size %d", b.Size())
+	}
+}
+
+func TestBufferPoolMatchesCapacity(t *testing.T) {
+	p := NewBufferPool()
+	small := p.Get(2)
+	p.Put(small)
+
+	for _, capacity := range []int{2, 8, 16} {
+		b := p.Get(capacity)
+		if b.capacity != capacity {
+			t.Errorf("Expected capacity %d, got %d", capacity, b.capacity)
+		}
+		for i := 0; i < capacity+1; i++ {
+			b.Push(float64(i))
+		}
+		if !b.IsFull() || b.Size() != capacity {
+			t.Errorf("Expected full buffer of size %d, got %d", capacity, b.Size())
+		}
+		p.Put(b)
+	}
+}
+
+func TestBufferPoolResetsState(t *testing.T) {
+	p := NewBufferPool()
+	b := p.Get(4)
+	b.PushWeighted(1.0, 5.0)
+	b.SetSampleRate(44100)
+	b.SetSkipInvalid(true)
+	b.EnableOccupancyTracking()
+	p.Put(b)
+
+	if b.Pushed() != 0 || b.SampleRate() != 0 || b.skipInvalid || b.occupancy != nil || b.weights != nil {
+		t.Errorf("Expected Put to reset the buffer to its new state")
+	}
+	if b.values[0] != nil {
+		t.Errorf("Expected Put to drop references to old values")
+	}
+}
+
+func TestBufferPoolRejectsNonDefault(t *testing.T) {
+	arena := NewArena(4)
+	watched := NewTypedBuffer(4)
+	watched.FullEvents()
+	streaming := NewTypedBuffer(4)
+	streaming.StreamChunks(2, func([]float64) {})
+	hooked := NewTypedBuffer(4)
+	hooked.SetOnResize(func(int, int) {})
+
+	for name, b := range map[string]*TypedBuffer{
+		"strict":    NewStrictTypedBuffer(4, reflect.Float64),
+		"unsafe":    NewTypedBufferUnsafe(4),
+		"arena":     arena.NewBuffer(4),
+		"watched":   watched,
+		"streaming": streaming,
+		"hooked":    hooked,
+	} {
+		p := NewBufferPool()
+		b.Push(1.0)
+		p.Put(b)
+		if b.Size() != 1 {
+			t.Errorf("%s: expected a rejected buffer to be left alone", name)
+		}
+		if p.Get(4) == b {
+			t.Errorf("%s: expected the buffer not to be pooled", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// The real cepstrum, for pitch detection.
+package types
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+const (
+	// The range of fundamentals PitchFromCepstrum searches, in Hz.
+	minCepstrumPitch = 50.0
+	maxCepstrumPitch = 2000.0
+)
+
+// Cepstrum returns the real cepstrum of the float64 values in the buffer: the inverse FFT of
+// the log magnitude of their FFT, indexed by quefrency in samples. The buffer capacity must be
+// a power of two, and is zero padded if not full.
+func (b *TypedBuffer) Cepstrum() ([]float64, error) {
+	values, err := b.fftValues()
+	if err != nil {
+		return nil, err
+	}
+	spectrum := fft.FFTReal(values)
+	for i, c := range spectrum {
+		// Offset slightly so that silent bins don't give -Inf.
+		spectrum[i] = complex(math.Log(cmplx.Abs(c)+1e-12), 0)
+	}
+
+	// The log magnitude is real and symmetric, so the result is real too.
+	result := make([]float64, len(spectrum))
+	for i, c := range fft.IFFT(spectrum) {
+		result[i] = real(c)
+	}
+	return result, nil
+}
+
+// PitchFromCepstrum estimates the fundamental frequency of the float64 values in the buffer,
+// from the largest peak in their cepstrum between 50Hz and 2kHz, refined between samples.
+// The buffer must span at least a couple of periods of the lowest pitch it is to detect.
+func (b *TypedBuffer) PitchFromCepstrum(sampleRate float64) (float64, error) {
+	if sampleRate <= 0 {
+		return 0, errors.New("PitchFromCepstrum sample rate must be positive")
+	}
+	cepstrum, err := b.Cepstrum()
+	if err != nil {
+		return 0, err
+	}
+
+	// Only the first half is searched, the rest mirrors it.
+	low := int(math.Ceil(sampleRate / maxCepstrumPitch))
+	high := int(math.Floor(sampleRate / minCepstrumPitch))
+	if low < 1 {
+		low = 1
+	}
+	if high > len(cepstrum)/2-1 {
+		high = len(cepstrum)/2 - 1
+	}
+	if low > high {
+		return 0, errors.New("Buffer too short to detect pitch at this sample rate")
+	}
+
+	at := low
+	for q := low; q <= high; q++ {
+		if cepstrum[q] > cepstrum[at] {
+			at = q
+		}
+	}
+
+	// Fit a parabola through the peak and its neighbours.
+	quefrency := float64(at)
+	before, peak, after := cepstrum[at-1], cepstrum[at], cepstrum[at+1]
+	if denominator := before - 2*peak + after; denominator != 0 {
+		quefrency += 0.5 * (before - after) / denominator
+	}
+	return sampleRate / quefrency, nil
+}
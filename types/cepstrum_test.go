@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// harmonicBuffer fills a buffer with a tone made of the first few harmonics of a fundamental.
+func harmonicBuffer(hz float64, sampleRate float64, capacity int) *TypedBuffer {
+	b := NewTypedBuffer(capacity)
+	for i := 0; i < capacity; i++ {
+		v := 0.0
+		for harmonic := 1.0; harmonic <= 8; harmonic++ {
+			v += math.Sin(2*math.Pi*harmonic*hz*float64(i)/sampleRate) / harmonic
+		}
+		b.Push(v)
+	}
+	return b
+}
+
+func TestPitchFromCepstrum(t *testing.T) {
+	for _, hz := range []float64{110, 220, 345} {
+		b := harmonicBuffer(hz, 16000, 4096)
+		actual, err := b.PitchFromCepstrum(16000)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if math.Abs(actual-hz) > 3 {
+			t.Errorf("Expected pitch near %f, got %f", hz, actual)
+		}
+	}
+}
+
+func TestCepstrumPeaksAtPeriod(t *testing.T) {
+	// 16000 / 200 is a whole period of 80 samples.
+	cepstrum, err := harmonicBuffer(200, 16000, 2048).Cepstrum()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cepstrum) != 2048 {
+		t.Fatalf("Expected 2048 quefrencies, got %d", len(cepstrum))
+	}
+	for q := 40; q < 120; q++ {
+		if q != 80 && cepstrum[q] > cepstrum[80] {
+			t.Errorf("Expected the peak at quefrency 80, but %d is larger", q)
+		}
+	}
+}
+
+func TestCepstrumNotPowerOf2(t *testing.T) {
+	if _, err := NewTypedBuffer(1000).Cepstrum(); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+	if _, err := NewTypedBuffer(1000).PitchFromCepstrum(16000); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+}
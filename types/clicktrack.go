@@ -0,0 +1,52 @@
+// A metronome, as a source of samples for testing.
+package types
+
+import (
+	"math"
+	"sync"
+)
+
+// ClickTrack returns a channel of float64 samples, at sampleRate, with a click every beat at the
+// given bpm and silence otherwise, suitable for GoPushChannel. Each click is clickLength samples
+// decaying linearly from 1. Beat n starts at sample round(n * 60 * sampleRate / bpm), so clicks
+// never drift however long it runs, but beats must be at least a sample apart. Samples are
+// produced until stop is called, after which the channel is closed.
+func ClickTrack(bpm float64, sampleRate float64, clickLength int) (<-chan interface{}, func()) {
+	if bpm <= 0 || sampleRate <= 0 {
+		panic("ClickTrack bpm and sample rate must be positive")
+	}
+	samplesPerBeat := 60 * sampleRate / bpm
+	if samplesPerBeat < 1 {
+		panic("ClickTrack beats must be at least one sample apart")
+	}
+
+	samples := make(chan interface{})
+	done := make(chan struct{})
+	go func() {
+		defer close(samples)
+		beat, lastClick, nextClick := 0, 0, 0
+		for i := 0; ; i++ {
+			if i >= nextClick {
+				beat++
+				lastClick, nextClick = i, int(math.Round(float64(beat)*samplesPerBeat))
+			}
+			value := 0.0
+			if sinceClick := i - lastClick; sinceClick < clickLength {
+				value = 1 - float64(sinceClick)/float64(clickLength)
+			}
+			select {
+			case samples <- value:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+	return samples, stop
+}
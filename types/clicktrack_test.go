@@ -0,0 +1,66 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClickTrackPositions(t *testing.T) {
+	// 100bpm at 1000Hz is a beat every 600 samples.
+	samples, stop := ClickTrack(100, 1000, 4)
+	defer stop()
+
+	for i := 0; i < 3000; i++ {
+		value := (<-samples).(float64)
+		expected := 0.0
+		if offset := i % 600; offset < 4 {
+			expected = 1 - float64(offset)/4
+		}
+		if value != expected {
+			t.Fatalf("Sample %d: expected %f, got %f", i, expected, value)
+		}
+	}
+}
+
+func TestClickTrackFractionalBeats(t *testing.T) {
+	// 7 beats a second at 1kHz is 142.857... samples per beat, so beats must be rounded.
+	samplesPerBeat := 60 * 1000.0 / 420
+	samples, stop := ClickTrack(420, 1000, 1)
+	defer stop()
+
+	clicks := []int{}
+	for i := 0; i < 20*int(samplesPerBeat); i++ {
+		if (<-samples).(float64) != 0 {
+			clicks = append(clicks, i)
+		}
+	}
+	if len(clicks) != 20 {
+		t.Fatalf("Expected 20 clicks, got %d", len(clicks))
+	}
+	for n, at := range clicks {
+		if expected := int(math.Round(float64(n) * samplesPerBeat)); at != expected {
+			t.Errorf("Click %d: expected at sample %d, got %d", n, expected, at)
+		}
+	}
+}
+
+func TestClickTrackTooFast(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic for beats less than a sample apart")
+		}
+	}()
+	ClickTrack(120000, 1000, 1)
+}
+
+func TestClickTrackStop(t *testing.T) {
+	samples, stop := ClickTrack(120, 8000, 10)
+	for i := 0; i < 100; i++ {
+		<-samples
+	}
+	stop()
+	stop()
+	// Draining must end, with the channel closed.
+	for range samples {
+	}
+}
@@ -0,0 +1,144 @@
+// G.711 mu-law and A-law companding of the float64 values held in a typed buffer.
+package types
+
+import (
+	"math"
+)
+
+const (
+	// Constants from the G.711 reference implementation.
+	muLawBias = 0x84
+	muLawClip = 8159
+)
+
+var (
+	// Largest value within each segment, for mu-law (14-bit) and A-law (13-bit) input.
+	muLawSegmentEnds = [8]int{0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF, 0x1FFF}
+	aLawSegmentEnds  = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+)
+
+// MuLawEncode converts the float64 values in the buffer, least recent first, to 8-bit mu-law codes.
+func (b *TypedBuffer) MuLawEncode() []byte {
+	values := b.floatValues()
+	result := make([]byte, len(values))
+	for i, v := range values {
+		result[i] = linearToMuLaw(floatToPCM(v))
+	}
+	return result
+}
+
+// MuLawDecode converts 8-bit mu-law codes back to float64 values in [-1, 1].
+func MuLawDecode(codes []byte) []float64 {
+	result := make([]float64, len(codes))
+	for i, c := range codes {
+		result[i] = float64(muLawToLinear(c)) / math.MaxInt16
+	}
+	return result
+}
+
+// ALawEncode converts the float64 values in the buffer, least recent first, to 8-bit A-law codes.
+func (b *TypedBuffer) ALawEncode() []byte {
+	values := b.floatValues()
+	result := make([]byte, len(values))
+	for i, v := range values {
+		result[i] = linearToALaw(floatToPCM(v))
+	}
+	return result
+}
+
+// ALawDecode converts 8-bit A-law codes back to float64 values in [-1, 1].
+func ALawDecode(codes []byte) []float64 {
+	result := make([]float64, len(codes))
+	for i, c := range codes {
+		result[i] = float64(aLawToLinear(c)) / math.MaxInt16
+	}
+	return result
+}
+
+// floatToPCM converts a [-1, 1] sample to 16-bit, clamping out of range values.
+func floatToPCM(v float64) int16 {
+	v = math.Max(-1.0, math.Min(1.0, v))
+	return int16(v * math.MaxInt16)
+}
+
+// segmentOf returns which segment a value lies in, or 8 if it is beyond all of them.
+func segmentOf(value int, ends [8]int) int {
+	for i, end := range ends {
+		if value <= end {
+			return i
+		}
+	}
+	return len(ends)
+}
+
+// linearToMuLaw encodes a 16-bit sample as mu-law.
+func linearToMuLaw(pcm int16) byte {
+	value := int(pcm) >> 2
+	mask := 0xFF
+	if value < 0 {
+		value = -value
+		mask = 0x7F
+	}
+	if value > muLawClip {
+		value = muLawClip
+	}
+	value += muLawBias >> 2
+
+	segment := segmentOf(value, muLawSegmentEnds)
+	if segment >= 8 {
+		return byte(0x7F ^ mask)
+	}
+	return byte(((segment << 4) | ((value >> uint(segment+1)) & 0xF)) ^ mask)
+}
+
+// muLawToLinear decodes a mu-law code to a 16-bit sample.
+func muLawToLinear(code byte) int16 {
+	u := int(^code)
+	t := ((u & 0xF) << 3) + muLawBias
+	t <<= uint((u & 0x70) >> 4)
+	if u&0x80 != 0 {
+		return int16(muLawBias - t)
+	}
+	return int16(t - muLawBias)
+}
+
+// linearToALaw encodes a 16-bit sample as A-law.
+func linearToALaw(pcm int16) byte {
+	value := int(pcm) >> 3
+	mask := 0xD5
+	if value < 0 {
+		value = -value - 1
+		mask = 0x55
+	}
+
+	segment := segmentOf(value, aLawSegmentEnds)
+	if segment >= 8 {
+		return byte(0x7F ^ mask)
+	}
+	code := segment << 4
+	if segment < 2 {
+		code |= (value >> 1) & 0xF
+	} else {
+		code |= (value >> uint(segment)) & 0xF
+	}
+	return byte(code ^ mask)
+}
+
+// aLawToLinear decodes an A-law code to a 16-bit sample.
+func aLawToLinear(code byte) int16 {
+	a := int(code ^ 0x55)
+	t := (a & 0xF) << 4
+	switch segment := (a & 0x70) >> 4; segment {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= uint(segment - 1)
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
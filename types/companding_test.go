@@ -0,0 +1,49 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompandingRoundTrip(t *testing.T) {
+	values := make([]float64, 2000)
+	for i := range values {
+		// Sweep across a wide range of levels, both signs.
+		values[i] = math.Sin(float64(i)*0.37) * math.Pow(10, -3*float64(i)/float64(len(values)))
+	}
+	b := newFloatBuffer(values)
+
+	codecs := []struct {
+		name   string
+		encode func() []byte
+		decode func([]byte) []float64
+	}{
+		{"mu-law", b.MuLawEncode, MuLawDecode},
+		{"A-law", b.ALawEncode, ALawDecode},
+	}
+	for _, codec := range codecs {
+		codes := codec.encode()
+		if len(codes) != len(values) {
+			t.Fatalf("%s: expected %d codes, got %d", codec.name, len(values), len(codes))
+		}
+		decoded := codec.decode(codes)
+		for i, v := range values {
+			// Logarithmic quantization: error grows with the level, plus a small floor.
+			tolerance := math.Abs(v)/16 + 32.0/math.MaxInt16
+			if math.Abs(decoded[i]-v) > tolerance {
+				t.Fatalf("%s sample %d: expected %f +/- %f, got %f", codec.name, i, v, tolerance, decoded[i])
+			}
+		}
+	}
+}
+
+func TestCompandingKnownCodes(t *testing.T) {
+	// Silence and full scale codes from G.711.
+	b := newFloatBuffer([]float64{0, 1, -1})
+	if codes := b.MuLawEncode(); codes[0] != 0xFF || codes[1] != 0x80 || codes[2] != 0x00 {
+		t.Errorf("Expected mu-law codes [ff 80 00], got %x", codes)
+	}
+	if codes := b.ALawEncode(); codes[0] != 0xD5 || codes[1] != 0xAA || codes[2] != 0x2A {
+		t.Errorf("Expected A-law codes [d5 aa 2a], got %x", codes)
+	}
+}
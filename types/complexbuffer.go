@@ -0,0 +1,44 @@
+// A circular buffer data type for complex values, e.g. frequency domain frames.
+package types
+
+import (
+	"math/cmplx"
+	"reflect"
+)
+
+// ComplexBuffer is a typed buffer that holds only complex128 values.
+type ComplexBuffer struct {
+	*TypedBuffer
+}
+
+// NewComplexBuffer creates a new circular buffer of complex values of a given maximum size.
+func NewComplexBuffer(capacity int) *ComplexBuffer {
+	return &ComplexBuffer{NewStrictTypedBuffer(capacity, reflect.Complex128)}
+}
+
+// Push adds a new value at the end of the buffer, returning the one it replaced (or zero).
+func (b *ComplexBuffer) Push(value complex128) complex128 {
+	result, _ := b.TypedBuffer.Push(value).(complex128)
+	return result
+}
+
+// Magnitudes returns the magnitudes of the values in the buffer, least recent first.
+func (b *ComplexBuffer) Magnitudes() []float64 {
+	return b.mapValues(cmplx.Abs)
+}
+
+// Phases returns the phases of the values in the buffer in radians, least recent first.
+func (b *ComplexBuffer) Phases() []float64 {
+	return b.mapValues(cmplx.Phase)
+}
+
+// mapValues applies a function to each value in the buffer, least recent first.
+func (b *ComplexBuffer) mapValues(f func(complex128) float64) []float64 {
+	result := make([]float64, 0, b.Size())
+	b.Each(func(i int, value interface{}) {
+		if c, ok := value.(complex128); ok {
+			result = append(result, f(c))
+		}
+	})
+	return result
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComplexBufferMagnitudesAndPhases(t *testing.T) {
+	b := NewComplexBuffer(3)
+	if evicted := b.Push(complex(1, 1)); evicted != 0 {
+		t.Fatalf("Expected nothing evicted, got %v", evicted)
+	}
+	b.Push(complex(0, 2))
+	b.Push(complex(-3, 0))
+	if evicted := b.Push(complex(0, -4)); evicted != complex(1, 1) {
+		t.Fatalf("Expected (1+1i) evicted, got %v", evicted)
+	}
+
+	assertClose(t, []float64{2, 3, 4}, b.Magnitudes(), 1e-12)
+	assertClose(t, []float64{math.Pi / 2, math.Pi, -math.Pi / 2}, b.Phases(), 1e-12)
+}
@@ -0,0 +1,13 @@
+// Diagnostics exposing the internal layout of a typed buffer.
+package types
+
+// DumpState returns a copy of the raw internal state of the buffer: the backing array exactly as
+// laid out in memory, plus the index the next value will be written to. This is intended only
+// for debugging and tests, callers shouldn't rely on the layout.
+func (b *TypedBuffer) DumpState() (capacity int, size int, at int, finished bool, raw []interface{}) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	raw = make([]interface{}, len(b.values))
+	copy(raw, b.values)
+	return b.capacity, b.size, b.at, b.finished, raw
+}
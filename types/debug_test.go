@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestDumpStateAcrossWrap(t *testing.T) {
+	b := NewTypedBuffer(4)
+	for i := 0; i < 6; i++ {
+		b.Push(float64(i))
+	}
+
+	capacity, size, at, finished, raw := b.DumpState()
+	if capacity != 4 || size != 4 || at != 2 || finished {
+		t.Fatalf("Expected capacity 4, size 4, at 2, unfinished; got %d, %d, %d, %v", capacity, size, at, finished)
+	}
+	assertValues(t, []float64{4, 5, 2, 3}, raw)
+
+	// The dump is a copy.
+	raw[0] = 100.0
+	if b.Freeze().At(2) != 4.0 {
+		t.Fatalf("Expected DumpState to copy the backing array")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	b := NewTypedBuffer(5)
+	for i := 0; i < 8; i++ {
+		b.Push(float64(i))
+	}
+	before := b.Freeze()
+
+	b.Compact()
+	if b.Freeze().Hash() != before.Hash() {
+		t.Fatalf("Expected contents unchanged by Compact")
+	}
+	_, size, at, _, raw := b.DumpState()
+	if size != 5 || at != 0 {
+		t.Fatalf("Expected size 5 and at 0, got %d and %d", size, at)
+	}
+	assertValues(t, []float64{3, 4, 5, 6, 7}, raw)
+
+	// Pushing afterwards carries on as normal.
+	b.Push(8.0)
+	assertValues(t, []float64{4, 5, 6, 7, 8}, b.Freeze().values)
+}
@@ -0,0 +1,67 @@
+// Noise reduction by spectral subtraction.
+package types
+
+import (
+	"errors"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// Denoiser removes a learnt noise spectrum from buffers of float64 values.
+type Denoiser struct {
+	// Magnitude of the noise in each frequency bin.
+	noise []float64
+}
+
+// NewDenoiser creates a denoiser that has not yet learnt any noise.
+func NewDenoiser() *Denoiser {
+	return &Denoiser{}
+}
+
+// LearnNoise captures the magnitude spectrum of a buffer holding only noise. The buffer
+// capacity must be a power of two, and buffers later processed need the same capacity.
+func (d *Denoiser) LearnNoise(b *TypedBuffer) error {
+	values, err := b.fftValues()
+	if err != nil {
+		return err
+	}
+	spectrum := fft.FFTReal(values)
+	d.noise = make([]float64, len(spectrum))
+	for i, c := range spectrum {
+		d.noise[i] = cmplx.Abs(c)
+	}
+	return nil
+}
+
+// Process returns the float64 values in the buffer with the learnt noise spectrum subtracted.
+// Each frequency bin keeps its phase, with its magnitude reduced by the noise (but not below zero).
+func (d *Denoiser) Process(b *TypedBuffer) ([]float64, error) {
+	if d.noise == nil {
+		return nil, errors.New("Denoiser has not learnt any noise")
+	}
+	values, err := b.fftValues()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(d.noise) {
+		return nil, errors.New("Buffer capacity differs from the learnt noise")
+	}
+
+	spectrum := fft.FFTReal(values)
+	for i, c := range spectrum {
+		magnitude, phase := cmplx.Polar(c)
+		magnitude -= d.noise[i]
+		if magnitude < 0 {
+			magnitude = 0
+		}
+		spectrum[i] = cmplx.Rect(magnitude, phase)
+	}
+
+	inverse := fft.IFFT(spectrum)
+	result := make([]float64, len(inverse))
+	for i, c := range inverse {
+		result[i] = real(c)
+	}
+	return result, nil
+}
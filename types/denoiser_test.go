@@ -0,0 +1,68 @@
+package types
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// noiseFloor returns the spectral energy of signal outside of the bins near a tone.
+func noiseFloor(signal []float64, toneBin int) float64 {
+	energy := 0.0
+	for i, c := range fft.FFTReal(signal)[:len(signal)/2] {
+		if i < toneBin-2 || i > toneBin+2 {
+			energy += math.Pow(cmplx.Abs(c), 2)
+		}
+	}
+	return energy
+}
+
+func TestDenoiserReducesNoiseFloor(t *testing.T) {
+	n, toneBin := 1024, 64
+	r := rand.New(rand.NewSource(1))
+	noise := func() float64 {
+		return 0.1 * (r.Float64() - 0.5)
+	}
+
+	noiseOnly := NewTypedBuffer(n)
+	noisy := NewTypedBuffer(n)
+	for i := 0; i < n; i++ {
+		noiseOnly.Push(noise())
+		noisy.Push(0.5*math.Sin(2*math.Pi*float64(toneBin*i)/float64(n)) + noise())
+	}
+
+	d := NewDenoiser()
+	if err := d.LearnNoise(noiseOnly); err != nil {
+		t.Fatalf("Error learning noise: %s", err)
+	}
+	denoised, err := d.Process(noisy)
+	if err != nil {
+		t.Fatalf("Error denoising: %s", err)
+	}
+
+	before, after := noiseFloor(noisy.floatValues(), toneBin), noiseFloor(denoised, toneBin)
+	if after > 0.5*before {
+		t.Errorf("Expected noise floor energy to at least halve, went from %f to %f", before, after)
+	}
+	// The tone itself should survive.
+	if amplitude := rms(denoised) * math.Sqrt2; amplitude < 0.4 {
+		t.Errorf("Expected the tone to be kept, got amplitude %f", amplitude)
+	}
+}
+
+func TestDenoiserRequiresPowerOfTwo(t *testing.T) {
+	d := NewDenoiser()
+	if err := d.LearnNoise(NewTypedBuffer(100)); err == nil {
+		t.Errorf("Expected error learning from a non power of two buffer")
+	}
+	if _, err := d.Process(NewTypedBuffer(128)); err == nil {
+		t.Errorf("Expected error processing before learning noise")
+	}
+	d.LearnNoise(NewTypedBuffer(128))
+	if _, err := d.Process(NewTypedBuffer(256)); err == nil {
+		t.Errorf("Expected error processing a buffer of different capacity")
+	}
+}
@@ -0,0 +1,17 @@
+// Errors returned by the Try variants of buffer methods.
+package types
+
+import (
+	"errors"
+)
+
+var (
+	// ErrIndexOutOfRange is returned when indexing outside of the buffer.
+	ErrIndexOutOfRange = errors.New("index out of range")
+	// ErrEmptyBuffer is returned when a value is needed but the buffer has none.
+	ErrEmptyBuffer = errors.New("buffer is empty")
+	// ErrInvalidCapacity is returned for capacities that can't be used.
+	ErrInvalidCapacity = errors.New("invalid capacity")
+	// ErrTypeMismatch is returned when a value isn't of the type needed.
+	ErrTypeMismatch = errors.New("type mismatch")
+)
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTryVariantsReturnSentinels(t *testing.T) {
+	if _, err := TryNewTypedBuffer(-1); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+	b, err := TryNewTypedBuffer(3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := b.TryNewest(); !errors.Is(err, ErrEmptyBuffer) {
+		t.Errorf("Expected ErrEmptyBuffer, got %v", err)
+	}
+	b.Push(1.0)
+	b.Push("two")
+	if value, err := b.TryNewest(); err != nil || value != "two" {
+		t.Errorf("Expected newest value two, got %v (%v)", value, err)
+	}
+
+	for _, index := range []int{-3, 3, 100} {
+		if _, err := b.TryGetFromEnd(index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Index %d: expected ErrIndexOutOfRange, got %v", index, err)
+		}
+	}
+	if _, err := b.TryGetFromEnd(2); err != nil {
+		t.Errorf("Expected an unfilled index to default, got %v", err)
+	}
+	if value, err := b.TryGetFromEnd(-1); err != nil || value != 1.0 {
+		t.Errorf("Expected -1 to be the oldest value 1, got %v (%v)", value, err)
+	}
+
+	if _, err := NewFluxTracker().Process(b); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity for a non power of two, got %v", err)
+	}
+}
+
+func TestTryGetFromEndFloat64TypeMismatch(t *testing.T) {
+	b := NewTypedBuffer(3)
+	b.Push(1.0)
+	b.Push("two")
+	b.Push(3.0)
+
+	mismatches := 0
+	for i := 0; i < 3; i++ {
+		if _, err := b.TryGetFromEndFloat64(i); errors.Is(err, ErrTypeMismatch) {
+			mismatches++
+		} else if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	if mismatches != 1 {
+		t.Errorf("Expected exactly one type mismatch, got %d", mismatches)
+	}
+}
+
+func TestGetFromEndStillPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected GetFromEnd to panic out of range")
+		}
+	}()
+	NewTypedBuffer(2).GetFromEnd(2)
+}
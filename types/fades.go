@@ -0,0 +1,44 @@
+// Fading the start and end of the float64 values held in a typed buffer.
+package types
+
+// FadeIn multiplies the first lengthSamples float64 values in the buffer by a smoothstep ramp
+// from zero, in place. The length is clamped to the number of values in the buffer.
+func (b *TypedBuffer) FadeIn(lengthSamples int) {
+	b.fade(lengthSamples, false)
+}
+
+// FadeOut multiplies the last lengthSamples float64 values in the buffer by a smoothstep ramp
+// down to zero, in place. The length is clamped to the number of values in the buffer.
+func (b *TypedBuffer) FadeOut(lengthSamples int) {
+	b.fade(lengthSamples, true)
+}
+
+// fade applies a smoothstep ramp to the float64 values at one end of the buffer.
+func (b *TypedBuffer) fade(lengthSamples int, fromEnd bool) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+
+	// Positions in b.values of the float64 values, least recent first.
+	positions := make([]int, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		if _, ok := b.values[b.physicalIndex(i)].(float64); ok {
+			positions = append(positions, b.physicalIndex(i))
+		}
+	}
+	if lengthSamples > len(positions) {
+		lengthSamples = len(positions)
+	}
+
+	for k := 0; k < lengthSamples; k++ {
+		at := positions[k]
+		if fromEnd {
+			at = positions[len(positions)-1-k]
+		}
+		b.values[at] = b.values[at].(float64) * smoothstep(float64(k)/float64(lengthSamples))
+	}
+}
+
+// smoothstep eases from 0 to 1 as x goes from 0 to 1, with zero slope at both ends.
+func smoothstep(x float64) float64 {
+	return x * x * (3 - 2*x)
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"testing"
+)
+
+// constantBuffer creates a full buffer holding a single value repeated.
+func constantBuffer(value float64, count int) *TypedBuffer {
+	b := NewTypedBuffer(count)
+	for i := 0; i < count; i++ {
+		b.Push(value)
+	}
+	return b
+}
+
+func TestFadeIn(t *testing.T) {
+	b := constantBuffer(1.0, 30)
+	b.FadeIn(10)
+	values := b.floatValues()
+
+	if values[0] > 1e-12 {
+		t.Errorf("Expected the first sample to be silent, got %f", values[0])
+	}
+	for i := 1; i < 10; i++ {
+		if values[i] <= values[i-1] || values[i] >= 1.0 {
+			t.Errorf("Expected a rising ramp, got %f then %f", values[i-1], values[i])
+		}
+	}
+	for i := 10; i < 30; i++ {
+		if values[i] != 1.0 {
+			t.Errorf("Expected sample %d untouched, got %f", i, values[i])
+		}
+	}
+}
+
+func TestFadeOut(t *testing.T) {
+	b := constantBuffer(0.5, 30)
+	// Wrap around, to make sure the real ends are faded.
+	for i := 0; i < 7; i++ {
+		b.Push(0.5)
+	}
+	b.FadeOut(10)
+	values := b.floatValues()
+
+	if values[29] > 1e-12 {
+		t.Errorf("Expected the last sample to be silent, got %f", values[29])
+	}
+	for i := 21; i < 30; i++ {
+		if values[i] >= values[i-1] {
+			t.Errorf("Expected a falling ramp, got %f then %f", values[i-1], values[i])
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if values[i] != 0.5 {
+			t.Errorf("Expected sample %d untouched, got %f", i, values[i])
+		}
+	}
+}
+
+func TestFadeClampsLength(t *testing.T) {
+	b := constantBuffer(1.0, 4)
+	b.FadeIn(100)
+	values := b.floatValues()
+	if values[0] != 0 || values[3] >= 1.0 {
+		t.Errorf("Expected the whole buffer to be faded, got %v", values)
+	}
+}
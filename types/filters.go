@@ -0,0 +1,141 @@
+// Signal filters over the float64 values held in a typed buffer.
+package types
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// DefaultEmphasis is the usual coefficient for pre-emphasis of speech.
+const DefaultEmphasis = 0.97
+
+// floatValues returns the float64 values in the buffer, least recent first.
+// Values of any other type are skipped, as are NaN and infinities if SetSkipInvalid is on.
+func (b *TypedBuffer) floatValues() []float64 {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.floatValuesLocked()
+}
+
+// floatValuesLocked is floatValues for callers already holding the lock.
+func (b *TypedBuffer) floatValuesLocked() []float64 {
+	result := make([]float64, 0, b.size)
+	b.eachLocked(func(i int, value interface{}) {
+		if f, ok := value.(float64); ok && !(b.skipInvalid && isInvalid(f)) {
+			result = append(result, f)
+		}
+	})
+	return result
+}
+
+// SetSkipInvalid sets whether the filters and measurements over float64 values ignore
+// any that are NaN or infinite, rather than letting them spoil the result.
+func (b *TypedBuffer) SetSkipInvalid(skip bool) {
+	b.lockBuffer()
+	b.skipInvalid = skip
+	b.unlockBuffer()
+}
+
+// HasInvalid returns whether any float64 values in the buffer are NaN or infinite.
+func (b *TypedBuffer) HasInvalid() bool {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	for i := 0; i < b.size; i++ {
+		if f, ok := b.values[b.physicalIndex(i)].(float64); ok && isInvalid(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeFloat replaces any NaN or infinite float64 values in the buffer with a replacement.
+func (b *TypedBuffer) SanitizeFloat(replacement float64) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	for i := 0; i < b.size; i++ {
+		at := b.physicalIndex(i)
+		if f, ok := b.values[at].(float64); ok && isInvalid(f) {
+			b.values[at] = replacement
+		}
+	}
+}
+
+// isInvalid returns whether a value is NaN or infinite.
+func isInvalid(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// MedianFilter returns, for each float64 value in the buffer, the median of the window
+// of values centred on it. The window must be odd, and values past either end are
+// clamped to the first or last value.
+func (b *TypedBuffer) MedianFilter(window int) ([]float64, error) {
+	if window <= 0 || window%2 == 0 {
+		return nil, errors.New("MedianFilter window must be positive and odd")
+	}
+	values := b.floatValues()
+	n := len(values)
+	half := window / 2
+
+	result := make([]float64, n)
+	sorted := make([]float64, window)
+	for i := range values {
+		for j := 0; j < window; j++ {
+			sorted[j] = values[clampIndex(i-half+j, n)]
+		}
+		sort.Float64s(sorted)
+		result[i] = sorted[half]
+	}
+	return result, nil
+}
+
+// WeightedMovingAverage returns, for each float64 value in the buffer, the dot product of the
+// weights with that value and those before it: weights[0] applies to the value itself,
+// weights[1] to the one before, and so on. Values before the first are clamped to the first.
+func (b *TypedBuffer) WeightedMovingAverage(weights []float64) ([]float64, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("WeightedMovingAverage requires at least one weight")
+	}
+	values := b.floatValues()
+	n := len(values)
+
+	result := make([]float64, n)
+	for i := range values {
+		sum := 0.0
+		for k, w := range weights {
+			sum += w * values[clampIndex(i-k, n)]
+		}
+		result[i] = sum
+	}
+	return result, nil
+}
+
+// PreEmphasis returns the float64 values in the buffer with high frequencies boosted,
+// as y[n] = x[n] - coeff * x[n-1]. The first value is unchanged.
+func (b *TypedBuffer) PreEmphasis(coeff float64) []float64 {
+	values := b.floatValues()
+	for i := len(values) - 1; i > 0; i-- {
+		values[i] -= coeff * values[i-1]
+	}
+	return values
+}
+
+// DeEmphasis undoes PreEmphasis on the float64 values in the buffer,
+// as y[n] = x[n] + coeff * y[n-1]. The first value is unchanged.
+func (b *TypedBuffer) DeEmphasis(coeff float64) []float64 {
+	values := b.floatValues()
+	for i := 1; i < len(values); i++ {
+		values[i] += coeff * values[i-1]
+	}
+	return values
+}
+
+// clampIndex limits an index to within [0, n).
+func clampIndex(index int, n int) int {
+	if index < 0 {
+		return 0
+	} else if index >= n {
+		return n - 1
+	}
+	return index
+}
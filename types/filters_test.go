@@ -0,0 +1,145 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// newFloatBuffer creates a buffer exactly big enough to hold the given values, and pushes them.
+func newFloatBuffer(values []float64) *TypedBuffer {
+	b := NewTypedBuffer(len(values))
+	for _, v := range values {
+		b.Push(v)
+	}
+	return b
+}
+
+// assertClose fails the test if two slices differ by more than a tolerance anywhere.
+func assertClose(t *testing.T, expected []float64, actual []float64, tolerance float64) {
+	if len(expected) != len(actual) {
+		t.Fatalf("Expected %d values, got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if math.Abs(expected[i]-actual[i]) > tolerance {
+			t.Fatalf("Value %d: expected %f, got %f", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestMedianFilterRemovesSpikes(t *testing.T) {
+	n := 50
+	clean := make([]float64, n)
+	noisy := make([]float64, n)
+	for i := range clean {
+		clean[i] = float64(i) / float64(n)
+		noisy[i] = clean[i]
+	}
+	noisy[10] = 1.0
+	noisy[30] = -1.0
+
+	filtered, err := newFloatBuffer(noisy).MedianFilter(3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// The ramp is preserved, with a spike replaced by one of its neighbours.
+	assertClose(t, clean, filtered, 1.0/float64(n)+1e-9)
+}
+
+func TestMedianFilterBadWindow(t *testing.T) {
+	b := newFloatBuffer([]float64{1, 2, 3})
+	for _, window := range []int{-1, 0, 2, 4} {
+		if _, err := b.MedianFilter(window); err == nil {
+			t.Errorf("Expected error for window %d", window)
+		}
+	}
+}
+
+func TestWeightedMovingAverageTriangular(t *testing.T) {
+	values := []float64{1, 2, 4, 8, 16, 32}
+	weights := []float64{3.0 / 9, 2.0 / 9, 1.0 / 9}
+
+	actual, err := newFloatBuffer(values).WeightedMovingAverage(weights)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := make([]float64, len(values))
+	for i := range values {
+		for k := range weights {
+			j := i - k
+			if j < 0 {
+				j = 0
+			}
+			expected[i] += weights[k] * values[j]
+		}
+	}
+	assertClose(t, expected, actual, 1e-12)
+	// Spot check one interior value by hand: (3*8 + 2*4 + 1*2) / 9
+	if math.Abs(actual[3]-34.0/9) > 1e-12 {
+		t.Errorf("Expected %f, got %f", 34.0/9, actual[3])
+	}
+}
+
+func TestWeightedMovingAverageNoWeights(t *testing.T) {
+	if _, err := newFloatBuffer([]float64{1}).WeightedMovingAverage(nil); err == nil {
+		t.Errorf("Expected error for empty weights")
+	}
+}
+
+func TestPreEmphasisBoostsHighFrequencies(t *testing.T) {
+	n := 64
+	constant := make([]float64, n)
+	alternating := make([]float64, n)
+	for i := 0; i < n; i++ {
+		constant[i] = 0.5
+		alternating[i] = 0.5 * math.Pow(-1, float64(i))
+	}
+
+	low := newFloatBuffer(constant).PreEmphasis(DefaultEmphasis)
+	high := newFloatBuffer(alternating).PreEmphasis(DefaultEmphasis)
+	if low[0] != 0.5 || high[0] != 0.5 {
+		t.Fatalf("Expected the first sample unchanged, got %f and %f", low[0], high[0])
+	}
+	// Skip the first sample, which is unfiltered.
+	if lowRMS, highRMS := rms(low[1:]), rms(high[1:]); highRMS < 10*lowRMS {
+		t.Errorf("Expected high frequencies boosted, got RMS %f (low) vs %f (high)", lowRMS, highRMS)
+	}
+}
+
+func TestDeEmphasisInvertsPreEmphasis(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = math.Sin(float64(i)*0.3) + 0.3*math.Sin(float64(i)*2.1)
+	}
+
+	emphasized := newFloatBuffer(values).PreEmphasis(DefaultEmphasis)
+	restored := newFloatBuffer(emphasized).DeEmphasis(DefaultEmphasis)
+	assertClose(t, values, restored, 1e-9)
+}
+
+func TestSanitizeFloat(t *testing.T) {
+	b := newFloatBuffer([]float64{0.1, math.NaN(), 0.3, math.Inf(1), math.Inf(-1), 0.6})
+	if !b.HasInvalid() {
+		t.Fatalf("Expected invalid values to be detected")
+	}
+
+	b.SanitizeFloat(0)
+	if b.HasInvalid() {
+		t.Fatalf("Expected no invalid values after sanitizing")
+	}
+	assertClose(t, []float64{0.1, 0, 0.3, 0, 0, 0.6}, b.floatValues(), 0)
+}
+
+func TestSkipInvalid(t *testing.T) {
+	b := newFloatBuffer([]float64{0.5, math.NaN(), 0.5, math.Inf(1)})
+	if unfiltered, _ := b.MedianFilter(1); !math.IsNaN(unfiltered[1]) {
+		t.Fatalf("Expected NaN to pass through by default, got %f", unfiltered[1])
+	}
+
+	b.SetSkipInvalid(true)
+	filtered, _ := b.MedianFilter(1)
+	assertClose(t, []float64{0.5, 0.5}, filtered, 0)
+	if !b.HasInvalid() {
+		t.Errorf("Expected skipping to leave the stored values alone")
+	}
+}
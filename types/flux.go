@@ -0,0 +1,57 @@
+// Spectral flux between successive windows, for onset detection.
+package types
+
+import (
+	"errors"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// FluxTracker measures how much the spectrum of a buffer changes each time it is processed.
+type FluxTracker struct {
+	window   []float64
+	previous []float64
+}
+
+// NewFluxTracker creates a tracker with no previous spectrum.
+func NewFluxTracker() *FluxTracker {
+	return &FluxTracker{}
+}
+
+// Process returns the sum of the increases in magnitude of each frequency bin between the
+// Hann windowed spectrum of the buffer and that of the previous buffer processed.
+// The first call has nothing to compare against, so returns zero. The buffer capacity must be
+// a power of two, and stay the same between calls.
+func (f *FluxTracker) Process(b *TypedBuffer) (float64, error) {
+	values, err := b.fftValues()
+	if err != nil {
+		return 0, err
+	}
+	if f.window == nil {
+		f.window = hannWindow(len(values))
+	} else if len(f.window) != len(values) {
+		return 0, errors.New("Buffer capacity differs from previous windows")
+	}
+	for i := range values {
+		values[i] *= f.window[i]
+	}
+
+	// Real input, so only the first half of the bins are needed.
+	spectrum := fft.FFTReal(values)[:len(values)/2+1]
+	magnitudes := make([]float64, len(spectrum))
+	for i, c := range spectrum {
+		magnitudes[i] = cmplx.Abs(c)
+	}
+
+	flux := 0.0
+	if f.previous != nil {
+		for i, m := range magnitudes {
+			if diff := m - f.previous[i]; diff > 0 {
+				flux += diff
+			}
+		}
+	}
+	f.previous = magnitudes
+	return flux, nil
+}
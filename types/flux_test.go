@@ -0,0 +1,52 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFluxTrackerSpikesOnChange(t *testing.T) {
+	n, sampleRate := 512, 8000.0
+	b := NewTypedBuffer(n)
+	f := NewFluxTracker()
+
+	// Steady tone, processed every hop samples.
+	hop, at := 128, 0
+	push := func(hz float64, count int) {
+		for i := 0; i < count; i++ {
+			b.Push(0.5 * math.Sin(2*math.Pi*hz*float64(at)/sampleRate))
+			at++
+		}
+	}
+
+	push(440, n)
+	if flux, err := f.Process(b); err != nil || flux != 0 {
+		t.Fatalf("Expected zero flux first, got %f (%v)", flux, err)
+	}
+	steady := 0.0
+	for i := 0; i < 8; i++ {
+		push(440, hop)
+		flux, _ := f.Process(b)
+		steady = math.Max(steady, flux)
+	}
+
+	// Abruptly change to a different, louder tone.
+	for i := 0; i < n; i++ {
+		b.Push(0.9 * math.Sin(2*math.Pi*1800*float64(i)/sampleRate))
+	}
+	changed, _ := f.Process(b)
+	if changed < 10*steady {
+		t.Errorf("Expected a flux spike on change, got %f versus steady %f", changed, steady)
+	}
+}
+
+func TestFluxTrackerRequiresPowerOfTwo(t *testing.T) {
+	f := NewFluxTracker()
+	if _, err := f.Process(NewTypedBuffer(100)); err == nil {
+		t.Errorf("Expected error for a non power of two buffer")
+	}
+	f.Process(NewTypedBuffer(64))
+	if _, err := f.Process(NewTypedBuffer(128)); err == nil {
+		t.Errorf("Expected error when the capacity changes")
+	}
+}
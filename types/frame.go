@@ -0,0 +1,57 @@
+// An immutable copy of a buffer's contents, for hashing and caching.
+package types
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Frame holds a copy of the values in a buffer at one point in time, plus a hash of them.
+type Frame struct {
+	values []interface{}
+	hash   uint64
+}
+
+// Freeze returns an immutable snapshot of the values in the buffer, least recent first.
+// []float64 values, like the frames of a FrameBuffer, are copied too. Any other values that
+// refer to shared memory, such as other slices, maps and pointers, are not, so changing what
+// they refer to changes the frame without updating its hash.
+func (b *TypedBuffer) Freeze() Frame {
+	b.lockBuffer()
+	values := b.snapshotLocked()
+	b.unlockBuffer()
+	for i, v := range values {
+		values[i] = copyIfFloats(v)
+	}
+
+	// Include the type, so that e.g. 1 and 1.0 hash differently.
+	h := fnv.New64a()
+	for _, v := range values {
+		fmt.Fprintf(h, "%T:%v\x00", v, v)
+	}
+	return Frame{values, h.Sum64()}
+}
+
+// Hash returns a hash of the frame contents, equal for frames with identical values.
+func (f Frame) Hash() uint64 {
+	return f.hash
+}
+
+// Len returns how many values are in the frame.
+func (f Frame) Len() int {
+	return len(f.values)
+}
+
+// At returns a value in the frame, with 0 being the least recent.
+// []float64 values are returned as a copy, so the frame can't be changed through them.
+func (f Frame) At(i int) interface{} {
+	return copyIfFloats(f.values[i])
+}
+
+// copyIfFloats returns a copy of a []float64 value, or any other value unchanged.
+func copyIfFloats(value interface{}) interface{} {
+	if floats, ok := value.([]float64); ok {
+		return append([]float64(nil), floats...)
+	}
+	return value
+}
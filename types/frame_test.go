@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestFreezeHash(t *testing.T) {
+	a := newFloatBuffer([]float64{1, 2, 3})
+	b := newFloatBuffer([]float64{1, 2, 3})
+	if a.Freeze().Hash() != b.Freeze().Hash() {
+		t.Fatalf("Expected identical contents to hash the same")
+	}
+
+	before := a.Freeze()
+	a.Push(4.0)
+	after := a.Freeze()
+	if before.Hash() == after.Hash() {
+		t.Fatalf("Expected a push to change the hash")
+	}
+
+	// The earlier frame is unaffected by the push.
+	if before.Len() != 3 || before.At(0) != 1.0 || before.At(2) != 3.0 {
+		t.Errorf("Expected frozen values [1 2 3], got %v", before.values)
+	}
+	if after.At(0) != 2.0 || after.At(2) != 4.0 {
+		t.Errorf("Expected frozen values [2 3 4], got %v", after.values)
+	}
+}
+
+func TestFreezeHashIncludesType(t *testing.T) {
+	a := NewTypedBuffer(1)
+	a.Push(1)
+	b := NewTypedBuffer(1)
+	b.Push(1.0)
+	if a.Freeze().Hash() == b.Freeze().Hash() {
+		t.Errorf("Expected int and float64 values to hash differently")
+	}
+}
+
+func TestFreezeCopiesFrames(t *testing.T) {
+	b := NewFrameBuffer(2, 2)
+	b.PushFrame([]float64{1, 2})
+	frozen := b.buffer.Freeze()
+	hash := frozen.Hash()
+
+	// Changing the frame through the frozen copy doesn't change it.
+	frozen.At(0).([]float64)[0] = 100
+	// Nor does changing the frame still held by the buffer.
+	b.Each(func(i int, frame []float64) {
+		frame[1] = 200
+	})
+
+	if actual := frozen.At(0).([]float64); actual[0] != 1 || actual[1] != 2 {
+		t.Errorf("Expected the frozen frame to stay [1 2], got %v", actual)
+	}
+	unchanged := NewFrameBuffer(2, 2)
+	unchanged.PushFrame([]float64{1, 2})
+	if unchanged.buffer.Freeze().Hash() != hash {
+		t.Errorf("Expected the hash to still match the frozen contents")
+	}
+}
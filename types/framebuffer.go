@@ -0,0 +1,92 @@
+// A circular buffer data type for multi-channel audio, one frame of samples per value.
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FrameBuffer is a circular buffer of frames: []float64 values with one sample per channel.
+// The typed buffer holding them isn't embedded, so that frames can only be added by PushFrame.
+type FrameBuffer struct {
+	buffer   *TypedBuffer
+	channels int
+}
+
+// NewFrameBuffer creates a new circular buffer of frames of a given maximum size,
+// each with the given number of channels.
+func NewFrameBuffer(capacity int, channels int) *FrameBuffer {
+	if channels < 1 {
+		panic(fmt.Sprintf("NewFrameBuffer needs at least one channel, got %d", channels))
+	}
+	return &FrameBuffer{NewStrictTypedBuffer(capacity, reflect.Slice), channels}
+}
+
+// Size returns the number of frames in the buffer.
+func (b *FrameBuffer) Size() int {
+	return b.buffer.Size()
+}
+
+// ElementKind returns reflect.Slice, the kind of each frame.
+func (b *FrameBuffer) ElementKind() reflect.Kind {
+	return b.buffer.ElementKind()
+}
+
+// Each applies a given function to all the frames in the buffer, least recent first.
+// The frames are shared with the buffer, so must not be modified.
+func (b *FrameBuffer) Each(cb func(int, []float64)) {
+	b.buffer.Each(func(i int, value interface{}) {
+		frame, _ := value.([]float64)
+		cb(i, frame)
+	})
+}
+
+// Channels returns how many samples are in each frame.
+func (b *FrameBuffer) Channels() int {
+	return b.channels
+}
+
+// PushFrame adds a copy of a frame at the end of the buffer, returning the one it replaced (or nil).
+// It panics if the frame doesn't have one sample per channel.
+func (b *FrameBuffer) PushFrame(frame []float64) []float64 {
+	if len(frame) != b.channels {
+		panic(fmt.Sprintf("PushFrame expected %d channels, got %d", b.channels, len(frame)))
+	}
+	result, _ := b.buffer.Push(append([]float64(nil), frame...)).([]float64)
+	return result
+}
+
+// DownmixToMono returns the average across channels of each frame, least recent first.
+func (b *FrameBuffer) DownmixToMono() []float64 {
+	weights := make([]float64, b.channels)
+	for i := range weights {
+		weights[i] = 1 / float64(b.channels)
+	}
+	return b.downmix(weights)
+}
+
+// DownmixWeighted returns the sum of each frame's samples times the weight of their channel,
+// least recent first. There must be one weight per channel.
+func (b *FrameBuffer) DownmixWeighted(weights []float64) ([]float64, error) {
+	if len(weights) != b.channels {
+		return nil, errors.New("DownmixWeighted needs one weight per channel")
+	}
+	return b.downmix(weights), nil
+}
+
+// downmix returns the weighted sum of each frame, least recent first.
+func (b *FrameBuffer) downmix(weights []float64) []float64 {
+	result := make([]float64, 0, b.Size())
+	b.Each(func(i int, frame []float64) {
+		// Every frame is pushed with one sample per channel, but never index past the weights.
+		if len(frame) == len(weights) {
+			sum := 0.0
+			for c, sample := range frame {
+				sum += weights[c] * sample
+			}
+			result = append(result, sum)
+		}
+	})
+	return result
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newStereoBuffer() *FrameBuffer {
+	b := NewFrameBuffer(3, 2)
+	for _, frame := range [][]float64{{9, 9}, {1, 3}, {-1, 1}, {0.5, 0}} {
+		b.PushFrame(frame)
+	}
+	return b
+}
+
+func TestDownmixToMono(t *testing.T) {
+	b := newStereoBuffer()
+	if b.Channels() != 2 || b.ElementKind() != reflect.Slice {
+		t.Fatalf("Expected a stereo buffer of slices")
+	}
+	assertClose(t, []float64{2, 0, 0.25}, b.DownmixToMono(), 1e-12)
+}
+
+func TestDownmixWeighted(t *testing.T) {
+	b := newStereoBuffer()
+	actual, err := b.DownmixWeighted([]float64{1, 0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertClose(t, []float64{1, -1, 0.5}, actual, 1e-12)
+
+	actual, err = b.DownmixWeighted([]float64{0.25, 0.5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertClose(t, []float64{1.75, 0.25, 0.125}, actual, 1e-12)
+
+	if _, err := b.DownmixWeighted([]float64{1}); err == nil {
+		t.Errorf("Expected an error for the wrong number of weights")
+	}
+}
+
+func TestPushFrameCopies(t *testing.T) {
+	b := NewFrameBuffer(2, 2)
+	frame := []float64{1, 2}
+	b.PushFrame(frame)
+	frame[0] = 100
+	assertClose(t, []float64{1.5}, b.DownmixToMono(), 1e-12)
+
+	b.PushFrame(frame)
+	if evicted := b.PushFrame(frame); !reflect.DeepEqual(evicted, []float64{1, 2}) {
+		t.Errorf("Expected [1 2] evicted, got %v", evicted)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic pushing a frame with the wrong channel count")
+		}
+	}()
+	b.PushFrame([]float64{1, 2, 3})
+}
+
+func TestFrameBufferOnlyPushesWholeFrames(t *testing.T) {
+	// The typed buffer push methods aren't promoted, so can't bypass the channel count.
+	for _, name := range []string{"Push", "PushUnique", "PushWeighted", "PushBlocking", "Swap"} {
+		if _, ok := reflect.TypeOf(&FrameBuffer{}).MethodByName(name); ok {
+			t.Errorf("Expected FrameBuffer not to expose %s", name)
+		}
+	}
+
+	b := NewFrameBuffer(4, 2)
+	for _, frame := range [][]float64{{1, 2, 3}, {1}, nil} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Expected PushFrame to panic for %d channels", len(frame))
+				}
+			}()
+			b.PushFrame(frame)
+		}()
+	}
+	if b.Size() != 0 {
+		t.Errorf("Expected no frames pushed, got %d", b.Size())
+	}
+	if mono := b.DownmixToMono(); len(mono) != 0 {
+		t.Errorf("Expected an empty downmix, got %v", mono)
+	}
+}
+
+func TestFrameBufferEach(t *testing.T) {
+	b := newStereoBuffer()
+	frames := [][]float64{}
+	b.Each(func(i int, frame []float64) {
+		frames = append(frames, frame)
+	})
+	expected := [][]float64{{1, 3}, {-1, 1}, {0.5, 0}}
+	if !reflect.DeepEqual(expected, frames) {
+		t.Errorf("Expected frames %v, got %v", expected, frames)
+	}
+}
@@ -0,0 +1,26 @@
+// Single frequency detection with the Goertzel algorithm.
+package types
+
+import (
+	"math"
+)
+
+// Goertzel returns the magnitude at targetFreq of the float64 values in the buffer, least recent
+// first, as the matching bin of a DFT over them would. This costs one multiply-add per value and
+// allocates nothing, so is much cheaper than a full FFT when only one frequency matters,
+// and the frequency need not fall exactly on a bin.
+func (b *TypedBuffer) Goertzel(targetFreq float64, sampleRate float64) float64 {
+	coefficient := 2 * math.Cos(2*math.Pi*targetFreq/sampleRate)
+
+	b.lockBuffer()
+	s1, s2 := 0.0, 0.0
+	b.eachLocked(func(i int, value interface{}) {
+		if f, ok := value.(float64); ok && !(b.skipInvalid && isInvalid(f)) {
+			s1, s2 = f+coefficient*s1-s2, s1
+		}
+	})
+	b.unlockBuffer()
+
+	// Clamp tiny negatives from rounding when the result is close to zero.
+	return math.Sqrt(math.Max(0, s1*s1+s2*s2-coefficient*s1*s2))
+}
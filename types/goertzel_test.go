@@ -0,0 +1,48 @@
+package types
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+func TestGoertzelRespondsNearTarget(t *testing.T) {
+	sampleRate := 8000.0
+	// The DTMF tones for the digit 1.
+	target := 697.0
+	onTarget := sineBuffer(1.0, target, sampleRate, 800).Goertzel(target, sampleRate)
+
+	// A sine of amplitude 1 over n samples has magnitude n / 2 in its bin.
+	if math.Abs(onTarget-400) > 20 {
+		t.Errorf("Expected magnitude near 400 on target, got %f", onTarget)
+	}
+	for _, hz := range []float64{770, 1209, 350} {
+		offTarget := sineBuffer(1.0, hz, sampleRate, 800).Goertzel(target, sampleRate)
+		if offTarget > 0.05*onTarget {
+			t.Errorf("Expected little response to %fHz, got %f against %f", hz, offTarget, onTarget)
+		}
+	}
+}
+
+func TestGoertzelMatchesFFTBin(t *testing.T) {
+	values := make([]float64, 64)
+	for i := range values {
+		values[i] = math.Sin(float64(i)*0.3) + 0.5*math.Cos(float64(i*i)*0.01)
+	}
+	spectrum := fft.FFTReal(values)
+	b := newFloatBuffer(values)
+	for _, bin := range []int{0, 3, 17, 32} {
+		expected := cmplx.Abs(spectrum[bin])
+		if actual := b.Goertzel(float64(bin), 64); math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("Bin %d: expected %f, got %f", bin, expected, actual)
+		}
+	}
+}
+
+func TestGoertzelEmpty(t *testing.T) {
+	if actual := NewTypedBuffer(10).Goertzel(440, 44100); actual != 0 {
+		t.Errorf("Expected 0 for an empty buffer, got %f", actual)
+	}
+}
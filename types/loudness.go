@@ -0,0 +1,134 @@
+// Approximate integrated loudness metering, loosely following ITU-R BS.1770.
+package types
+
+import (
+	"math"
+)
+
+const (
+	// Gating block length and step, in seconds.
+	loudnessBlockSeconds = 0.4
+	loudnessStepSeconds  = 0.1
+	// Blocks quieter than this are ignored entirely, in LUFS.
+	loudnessAbsoluteGate = -70.0
+	// Blocks this much quieter than the ungated mean are also ignored, in LU.
+	loudnessRelativeGate = -10.0
+	// Offset that makes a K-weighted full-scale mono 1kHz sine read about -3 LUFS.
+	loudnessOffset = -0.691
+)
+
+// biquad is a direct form I second order IIR filter.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newBiquad creates a biquad filter, normalizing the coefficients by a0.
+func newBiquad(b0, b1, b2, a0, a1, a2 float64) *biquad {
+	return &biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// process filters the next sample.
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two stage K-weighting filter for a given sample rate:
+// a high shelf modelling the head, followed by a high pass.
+func kWeighting(sampleRate float64) (*biquad, *biquad) {
+	// High shelf, +4dB above ~1.7kHz.
+	gain, q, fc := 3.999843853973347, 0.7071752369554196, 1681.974450955533
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * fc / sampleRate
+	alpha := math.Sin(w0) / (2 * q)
+	cosW0, sqrtA := math.Cos(w0), math.Sqrt(a)
+	shelf := newBiquad(
+		a*((a+1)+(a-1)*cosW0+2*sqrtA*alpha),
+		-2*a*((a-1)+(a+1)*cosW0),
+		a*((a+1)+(a-1)*cosW0-2*sqrtA*alpha),
+		(a+1)-(a-1)*cosW0+2*sqrtA*alpha,
+		2*((a-1)-(a+1)*cosW0),
+		(a+1)-(a-1)*cosW0-2*sqrtA*alpha,
+	)
+
+	// High pass at ~38Hz.
+	q, fc = 0.5003270373238773, 38.13547087602444
+	w0 = 2 * math.Pi * fc / sampleRate
+	alpha = math.Sin(w0) / (2 * q)
+	highPass := newBiquad(1, -2, 1, 1+alpha, -2*math.Cos(w0), 1-alpha)
+	return shelf, highPass
+}
+
+// IntegratedLoudness returns an estimate of the loudness of the float64 values in the buffer, in LUFS.
+// The values are K-weighted, split into overlapping 400ms blocks, and quiet blocks are gated out
+// before averaging. This is a simplified approximation of BS.1770, not a certified meter.
+// Buffers shorter than one block are measured as a single block, and if every block is gated
+// out, negative infinity is returned.
+func (b *TypedBuffer) IntegratedLoudness(sampleRate float64) float64 {
+	values := b.floatValues()
+	if len(values) == 0 {
+		return math.Inf(-1)
+	}
+
+	shelf, highPass := kWeighting(sampleRate)
+	for i, v := range values {
+		values[i] = highPass.process(shelf.process(v))
+	}
+
+	blockSize := int(loudnessBlockSeconds * sampleRate)
+	step := int(loudnessStepSeconds * sampleRate)
+	if blockSize > len(values) {
+		blockSize = len(values)
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	powers := []float64{}
+	for start := 0; start+blockSize <= len(values); start += step {
+		sum := 0.0
+		for _, v := range values[start : start+blockSize] {
+			sum += v * v
+		}
+		powers = append(powers, sum/float64(blockSize))
+	}
+
+	gated := gateLoudness(powers, loudnessAbsoluteGate)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	relative := powerToLoudness(meanOf(gated)) + loudnessRelativeGate
+	gated = gateLoudness(gated, relative)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	return powerToLoudness(meanOf(gated))
+}
+
+// gateLoudness returns the block powers that are louder than a threshold in LUFS.
+func gateLoudness(powers []float64, threshold float64) []float64 {
+	result := []float64{}
+	for _, p := range powers {
+		if powerToLoudness(p) > threshold {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// powerToLoudness converts a mean square of K-weighted samples to LUFS.
+func powerToLoudness(power float64) float64 {
+	return loudnessOffset + 10*math.Log10(power)
+}
+
+// meanOf returns the average of some values.
+func meanOf(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// sineBuffer creates a full buffer holding a sine wave.
+func sineBuffer(amplitude float64, hz float64, sampleRate float64, count int) *TypedBuffer {
+	b := NewTypedBuffer(count)
+	for i := 0; i < count; i++ {
+		b.Push(amplitude * math.Sin(2*math.Pi*hz*float64(i)/sampleRate))
+	}
+	return b
+}
+
+func TestIntegratedLoudnessCalibrationTone(t *testing.T) {
+	// A mono 1kHz sine with RMS of -23dBFS should measure close to -23 LUFS.
+	sampleRate := 48000.0
+	amplitude := math.Sqrt2 * math.Pow(10, -23.0/20)
+	b := sineBuffer(amplitude, 1000, sampleRate, int(5*sampleRate))
+
+	tolerance := 0.3
+	if actual := b.IntegratedLoudness(sampleRate); math.Abs(actual+23) > tolerance {
+		t.Errorf("Expected -23 +/- %.1f LUFS, got %f", tolerance, actual)
+	}
+}
+
+func TestIntegratedLoudnessGatesSilence(t *testing.T) {
+	sampleRate := 44100.0
+	amplitude := math.Sqrt2 * math.Pow(10, -23.0/20)
+	tone := sineBuffer(amplitude, 1000, sampleRate, int(3*sampleRate))
+
+	// Padding with silence would be 3dB quieter ungated, but should barely change once gated.
+	padded := NewTypedBuffer(2 * tone.Size())
+	tone.EachFloat64(func(i int, v float64) {
+		padded.Push(v)
+	})
+	for i := 0; i < tone.Size(); i++ {
+		padded.Push(0.0)
+	}
+
+	expected := tone.IntegratedLoudness(sampleRate)
+	if actual := padded.IntegratedLoudness(sampleRate); math.Abs(actual-expected) > 0.5 {
+		t.Errorf("Expected %f LUFS with silence gated, got %f", expected, actual)
+	}
+	if silent := NewTypedBuffer(10).IntegratedLoudness(sampleRate); !math.IsInf(silent, -1) {
+		t.Errorf("Expected -Inf loudness for an empty buffer, got %f", silent)
+	}
+}
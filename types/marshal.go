@@ -0,0 +1,64 @@
+// Compact serialization of typed buffers of float64 values.
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// float32HeaderSize is the length of the header: capacity, size and sample rate as uint32s.
+	float32HeaderSize = 12
+	// maxFloat32Capacity is the largest capacity UnmarshalFloat32 will allocate,
+	// so that a corrupt or hostile header can't exhaust memory.
+	maxFloat32Capacity = 1 << 24
+)
+
+// MarshalFloat32 serializes the float64 values in the buffer, least recent first, as little-endian
+// float32s to halve the space needed. This loses precision beyond that of a float32. A header
+// holds the capacity, number of values and sample rate (0 if not set).
+func (b *TypedBuffer) MarshalFloat32() []byte {
+	b.lockBuffer()
+	values := b.floatValuesLocked()
+	capacity, sampleRate := b.capacity, b.sampleRate
+	b.unlockBuffer()
+
+	result := make([]byte, float32HeaderSize+4*len(values))
+	binary.LittleEndian.PutUint32(result[0:], uint32(capacity))
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(values)))
+	binary.LittleEndian.PutUint32(result[8:], uint32(sampleRate))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(result[float32HeaderSize+4*i:], math.Float32bits(float32(v)))
+	}
+	return result
+}
+
+// UnmarshalFloat32 restores a buffer serialized by MarshalFloat32.
+// Capacities over 2^24 values are rejected, rather than trusted and allocated.
+func UnmarshalFloat32(data []byte) (*TypedBuffer, error) {
+	if len(data) < float32HeaderSize {
+		return nil, errors.New("Float32 data too short for header")
+	}
+	capacity := int(binary.LittleEndian.Uint32(data[0:]))
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	sampleRate := int(binary.LittleEndian.Uint32(data[8:]))
+	if capacity > maxFloat32Capacity {
+		return nil, fmt.Errorf("%w: float32 data capacity %d is over the limit of %d", ErrInvalidCapacity, capacity, maxFloat32Capacity)
+	}
+	if size > capacity {
+		return nil, errors.New("Float32 data holds more values than its capacity")
+	}
+	if len(data) != float32HeaderSize+4*size {
+		return nil, errors.New("Float32 data length doesn't match its header")
+	}
+
+	b := NewTypedBuffer(capacity)
+	b.sampleRate = sampleRate
+	for i := 0; i < size; i++ {
+		bits := binary.LittleEndian.Uint32(data[float32HeaderSize+4*i:])
+		b.Push(float64(math.Float32frombits(bits)))
+	}
+	return b, nil
+}
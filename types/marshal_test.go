@@ -0,0 +1,55 @@
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMarshalFloat32RoundTrip(t *testing.T) {
+	b := NewTypedBuffer(8)
+	b.SetSampleRate(44100)
+	for i := 0; i < 5; i++ {
+		b.Push(math.Sin(float64(i)) / 3)
+	}
+
+	data := b.MarshalFloat32()
+	if len(data) != 12+4*5 {
+		t.Fatalf("Expected %d bytes, got %d", 12+4*5, len(data))
+	}
+
+	restored, err := UnmarshalFloat32(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if restored.capacity != 8 || restored.Size() != 5 || restored.SampleRate() != 44100 {
+		t.Fatalf("Expected capacity 8, size 5, rate 44100; got %d, %d, %d",
+			restored.capacity, restored.Size(), restored.SampleRate())
+	}
+	// float32 has a 24 bit mantissa.
+	assertClose(t, b.floatValues(), restored.floatValues(), 1e-7)
+}
+
+func TestUnmarshalFloat32Invalid(t *testing.T) {
+	data := newFloatBuffer([]float64{1, 2, 3}).MarshalFloat32()
+	for _, bad := range [][]byte{data[:5], data[:len(data)-1]} {
+		if _, err := UnmarshalFloat32(bad); err == nil {
+			t.Errorf("Expected error for %d bytes of data", len(bad))
+		}
+	}
+}
+
+func TestUnmarshalFloat32HugeCapacity(t *testing.T) {
+	// A bare header claiming the largest possible capacity must not be allocated.
+	data := make([]byte, float32HeaderSize)
+	binary.LittleEndian.PutUint32(data[0:], math.MaxUint32)
+	if _, err := UnmarshalFloat32(data); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+
+	binary.LittleEndian.PutUint32(data[0:], maxFloat32Capacity+1)
+	if _, err := UnmarshalFloat32(data); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity just over the limit, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Onset detection using an adaptive energy threshold.
+package types
+
+import (
+	"sort"
+)
+
+const (
+	// Length of each frame whose energy is measured, in seconds.
+	onsetFrameSeconds = 0.01
+	// How many previous frame energies the threshold adapts to.
+	onsetHistoryFrames = 50
+	// How many times louder than the local median a frame must be to count as an onset.
+	onsetThreshold = 4.0
+	// Minimum frame energy for an onset, so that near-silence never triggers.
+	onsetMinEnergy = 1e-6
+)
+
+// OnsetDetector finds the starts of new sounds within a stream of samples.
+type OnsetDetector struct {
+	frameSize int
+	history   *Buffer
+
+	// Energy accumulated in the current frame so far.
+	frameAt     int
+	frameEnergy float64
+	// Whether the last frame was an onset, to avoid firing twice for one sound.
+	lastOnset bool
+}
+
+// NewOnsetDetector creates a detector for a stream of samples at the given rate.
+func NewOnsetDetector(sampleRate float64) *OnsetDetector {
+	frameSize := int(sampleRate * onsetFrameSeconds)
+	if frameSize < 1 {
+		frameSize = 1
+	}
+	return &OnsetDetector{
+		frameSize: frameSize,
+		history:   NewBuffer(onsetHistoryFrames),
+	}
+}
+
+// Process adds the next sample, and returns true if it completes a frame
+// whose energy is well above the median of recent frames.
+func (d *OnsetDetector) Process(sample float64) bool {
+	d.frameEnergy += sample * sample
+	d.frameAt++
+	if d.frameAt < d.frameSize {
+		return false
+	}
+
+	energy := d.frameEnergy / float64(d.frameSize)
+	d.frameAt, d.frameEnergy = 0, 0.0
+
+	isOnset := energy > onsetMinEnergy && energy > onsetThreshold*d.medianEnergy()
+	fired := isOnset && !d.lastOnset
+	d.lastOnset = isOnset
+	d.history.Push(energy)
+	return fired
+}
+
+// medianEnergy returns the median of the recent frame energies.
+func (d *OnsetDetector) medianEnergy() float64 {
+	if d.history.Size() == 0 {
+		return 0.0
+	}
+	energies := make([]float64, 0, d.history.Size())
+	d.history.Each(func(i int, energy float64) {
+		energies = append(energies, energy)
+	})
+	sort.Float64s(energies)
+	return energies[len(energies)/2]
+}
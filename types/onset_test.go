@@ -0,0 +1,46 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOnsetDetectorClickTrack(t *testing.T) {
+	sampleRate := 44100.0
+	clicks := []int{10000, 32050, 55000, 70123}
+	length := 88200
+
+	signal := make([]float64, length)
+	for _, at := range clicks {
+		for i := 0; i < 200; i++ {
+			signal[at+i] = 0.9 * math.Exp(-float64(i)/40.0)
+		}
+	}
+
+	d := NewOnsetDetector(sampleRate)
+	detected := []int{}
+	for i, v := range signal {
+		if d.Process(v) {
+			detected = append(detected, i)
+		}
+	}
+
+	if len(detected) != len(clicks) {
+		t.Fatalf("Expected onsets near %v, got %v", clicks, detected)
+	}
+	for i, at := range clicks {
+		// Onsets are reported at the end of the frame containing the click.
+		if detected[i] < at || detected[i] > at+2*d.frameSize {
+			t.Errorf("Expected onset near %d, got %d", at, detected[i])
+		}
+	}
+}
+
+func TestOnsetDetectorSilence(t *testing.T) {
+	d := NewOnsetDetector(8000)
+	for i := 0; i < 8000; i++ {
+		if d.Process(0.0) {
+			t.Fatalf("Unexpected onset in silence at %d", i)
+		}
+	}
+}
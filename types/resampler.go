@@ -0,0 +1,83 @@
+// Sample rate conversion with a windowed-sinc anti-aliasing filter.
+package types
+
+import (
+	"math"
+)
+
+// Resampler converts a stream of samples from one rate to another, low-pass filtering at the
+// Nyquist of the lower rate so that downsampling does not alias. State is kept between calls
+// to Process, so a stream can be converted a block at a time.
+type Resampler struct {
+	// Source samples per output sample.
+	step float64
+	// Filter cutoff, as a fraction of the source Nyquist.
+	cutoff float64
+	// Number of taps either side of each output position.
+	half int
+
+	// Source samples still needed by the filter, and the position of the next output within them.
+	history  []float64
+	position float64
+}
+
+// NewResampler creates a resampler from srcRate to dstRate, using a Blackman windowed sinc
+// filter with the given number of taps. More taps give a sharper filter, at more cost.
+func NewResampler(srcRate float64, dstRate float64, taps int) *Resampler {
+	if srcRate <= 0 || dstRate <= 0 {
+		panic("NewResampler rates must be positive")
+	}
+	if taps < 2 {
+		panic("NewResampler needs at least two taps")
+	}
+	half := taps / 2
+	return &Resampler{
+		step:   srcRate / dstRate,
+		cutoff: math.Min(1, dstRate/srcRate),
+		half:   half,
+		// Start with silence before the first sample, so the first output lines up with it.
+		history:  make([]float64, half),
+		position: float64(half),
+	}
+}
+
+// Process takes the next block of source samples, and returns all output samples that can be
+// produced from them. Output lags input by half the filter length.
+func (r *Resampler) Process(input []float64) []float64 {
+	r.history = append(r.history, input...)
+
+	output := []float64{}
+	for int(r.position)+r.half < len(r.history) {
+		output = append(output, r.sampleAt(r.position))
+		r.position += r.step
+	}
+
+	// Drop the samples that no later output will need.
+	if drop := int(r.position) - r.half + 1; drop > 0 {
+		r.history = append(r.history[:0], r.history[drop:]...)
+		r.position -= float64(drop)
+	}
+	return output
+}
+
+// sampleAt applies the filter centred at a fractional position within the history.
+func (r *Resampler) sampleAt(position float64) float64 {
+	centre := int(position)
+	sum := 0.0
+	for k := centre - r.half + 1; k <= centre+r.half; k++ {
+		sum += r.history[k] * r.kernel(position-float64(k))
+	}
+	return sum
+}
+
+// kernel is the windowed sinc low-pass, evaluated at an offset in source samples.
+func (r *Resampler) kernel(offset float64) float64 {
+	x := r.cutoff * offset
+	sinc := 1.0
+	if x != 0 {
+		sinc = math.Sin(math.Pi*x) / (math.Pi * x)
+	}
+	w := math.Pi * offset / float64(r.half)
+	window := 0.42 + 0.5*math.Cos(w) + 0.08*math.Cos(2*w)
+	return r.cutoff * sinc * window
+}
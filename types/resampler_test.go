@@ -0,0 +1,71 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// resampleTone converts a one second sine from 48kHz to 16kHz, a block at a time.
+func resampleTone(hz float64) []float64 {
+	r := NewResampler(48000, 16000, 64)
+	output := []float64{}
+	for block := 0; block < 48; block++ {
+		input := make([]float64, 1000)
+		for i := range input {
+			input[i] = math.Sin(2 * math.Pi * hz * float64(block*1000+i) / 48000)
+		}
+		output = append(output, r.Process(input)...)
+	}
+	return output
+}
+
+func TestResamplerLength(t *testing.T) {
+	// Less the filter delay, one second of input should give almost one second of output.
+	if actual := len(resampleTone(1000)); actual < 15980 || actual > 16000 {
+		t.Errorf("Expected close to 16000 samples, got %d", actual)
+	}
+}
+
+func TestResamplerPassband(t *testing.T) {
+	output := resampleTone(1000)
+	if actual := rms(output[100:]); math.Abs(actual-1/math.Sqrt2) > 0.01 {
+		t.Errorf("Expected a passband tone to keep RMS %f, got %f", 1/math.Sqrt2, actual)
+	}
+}
+
+func TestResamplerAttenuatesAboveNyquist(t *testing.T) {
+	// 12kHz is above the 8kHz output Nyquist, and would alias to 4kHz unfiltered.
+	output := resampleTone(12000)[100:]
+	alias := newFloatBuffer(output).Goertzel(4000, 16000)
+	reference := newFloatBuffer(resampleTone(4000)[100:]).Goertzel(4000, 16000)
+	if ratio := 20 * math.Log10(alias/reference); ratio > -60 {
+		t.Errorf("Expected the alias at least 60dB down, got %fdB", ratio)
+	}
+}
+
+func TestResamplerBlocksMatchWhole(t *testing.T) {
+	input := make([]float64, 999)
+	for i := range input {
+		input[i] = math.Sin(float64(i) * 0.1)
+	}
+	whole := NewResampler(44100, 32000, 32).Process(input)
+
+	r := NewResampler(44100, 32000, 32)
+	split := []float64{}
+	for start := 0; start < len(input); start += 37 {
+		end := start + 37
+		if end > len(input) {
+			end = len(input)
+		}
+		split = append(split, r.Process(input[start:end])...)
+	}
+
+	assertClose(t, whole, split, 1e-9)
+}
+
+func TestResamplerUpsample(t *testing.T) {
+	output := NewResampler(8000, 16000, 32).Process(make([]float64, 100))
+	if len(output) < 150 || len(output) > 200 {
+		t.Errorf("Expected roughly twice the samples, got %d", len(output))
+	}
+}
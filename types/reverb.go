@@ -0,0 +1,51 @@
+// Comb and allpass filters, the building blocks of a Schroeder reverb.
+package types
+
+import (
+	"fmt"
+)
+
+// CombFilter is a feedback comb filter: y[n] = x[n] + gain * y[n - delay]
+type CombFilter struct {
+	gain  float64
+	delay *Buffer
+}
+
+// NewCombFilter creates a feedback comb filter with a given delay in samples, and feedback gain.
+func NewCombFilter(delaySamples int, gain float64) *CombFilter {
+	if delaySamples < 1 {
+		panic(fmt.Sprintf("NewCombFilter delay must be at least one sample, got %d", delaySamples))
+	}
+	return &CombFilter{gain, NewBuffer(delaySamples)}
+}
+
+// Process filters the next sample.
+func (f *CombFilter) Process(sample float64) float64 {
+	result := sample + f.gain*f.delay.oldest()
+	f.delay.Push(result)
+	return result
+}
+
+// AllpassFilter is a Schroeder allpass filter: y[n] = -gain * x[n] + x[n - delay] + gain * y[n - delay]
+// It passes all frequencies with equal gain, while smearing their phases.
+type AllpassFilter struct {
+	gain  float64
+	delay *Buffer
+}
+
+// NewAllpassFilter creates an allpass filter with a given delay in samples, and feedback gain.
+func NewAllpassFilter(delaySamples int, gain float64) *AllpassFilter {
+	if delaySamples < 1 {
+		panic(fmt.Sprintf("NewAllpassFilter delay must be at least one sample, got %d", delaySamples))
+	}
+	return &AllpassFilter{gain, NewBuffer(delaySamples)}
+}
+
+// Process filters the next sample.
+func (f *AllpassFilter) Process(sample float64) float64 {
+	// Uses a single delay line, holding v[n] = x[n] + gain * v[n - delay]
+	delayed := f.delay.oldest()
+	v := sample + f.gain*delayed
+	f.delay.Push(v)
+	return -f.gain*v + delayed
+}
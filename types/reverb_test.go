@@ -0,0 +1,64 @@
+package types
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+func TestCombFilterEchoes(t *testing.T) {
+	delay, gain := 5, 0.6
+	f := NewCombFilter(delay, gain)
+	for i := 0; i < 40; i++ {
+		input := 0.0
+		if i == 0 {
+			input = 1.0
+		}
+		expected := 0.0
+		if i%delay == 0 {
+			expected = math.Pow(gain, float64(i/delay))
+		}
+		if actual := f.Process(input); math.Abs(actual-expected) > 1e-12 {
+			t.Fatalf("Sample %d: expected %f, got %f", i, expected, actual)
+		}
+	}
+}
+
+func TestAllpassFilterFlatResponse(t *testing.T) {
+	f := NewAllpassFilter(7, 0.5)
+	impulse := make([]float64, 1024)
+	for i := range impulse {
+		input := 0.0
+		if i == 0 {
+			input = 1.0
+		}
+		impulse[i] = f.Process(input)
+	}
+
+	if impulse[0] != -0.5 {
+		t.Fatalf("Expected an immediate response of -gain, got %f", impulse[0])
+	}
+	for i, c := range fft.FFTReal(impulse) {
+		if magnitude := cmplx.Abs(c); math.Abs(magnitude-1.0) > 1e-6 {
+			t.Fatalf("Bin %d: expected unit magnitude, got %f", i, magnitude)
+		}
+	}
+}
+
+func TestFiltersRejectEmptyDelay(t *testing.T) {
+	for name, create := range map[string]func(){
+		"comb":    func() { NewCombFilter(0, 0.5) },
+		"allpass": func() { NewAllpassFilter(-1, 0.5) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s: expected a panic for a delay under one sample", name)
+				}
+			}()
+			create()
+		}()
+	}
+}
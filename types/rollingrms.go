@@ -0,0 +1,46 @@
+// RMS over a sliding window, updated in constant time per sample.
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// RollingRMS tracks the root mean square of the most recent samples.
+type RollingRMS struct {
+	window     *Buffer
+	sumSquares float64
+	// Pushes until the sum is next recomputed from scratch, to stop rounding errors building up.
+	untilRecompute int
+}
+
+// NewRollingRMS creates a tracker over a window of the given number of samples.
+func NewRollingRMS(window int) *RollingRMS {
+	if window < 1 {
+		panic(fmt.Sprintf("NewRollingRMS window must be at least one sample, got %d", window))
+	}
+	return &RollingRMS{
+		window:         NewBuffer(window),
+		untilRecompute: window,
+	}
+}
+
+// Push adds the next sample, and returns the RMS of the window now ending with it.
+func (r *RollingRMS) Push(sample float64) float64 {
+	evicted := r.window.Push(sample)
+	r.sumSquares += sample*sample - evicted*evicted
+
+	r.untilRecompute--
+	if r.untilRecompute <= 0 {
+		r.untilRecompute = r.window.capacity
+		r.sumSquares = 0
+		r.window.Each(func(i int, value float64) {
+			r.sumSquares += value * value
+		})
+	}
+
+	if r.sumSquares <= 0 {
+		return 0.0
+	}
+	return math.Sqrt(r.sumSquares / float64(r.window.Size()))
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRollingRMSMatchesRecomputation(t *testing.T) {
+	window := 100
+	r := NewRollingRMS(window)
+	random := rand.New(rand.NewSource(1))
+
+	signal := make([]float64, 20000)
+	for i := range signal {
+		// Large swings in level, which are worst for accumulated rounding.
+		level := 1.0
+		if (i/3000)%2 == 1 {
+			level = 1e-3
+		}
+		signal[i] = level * (2*random.Float64() - 1)
+	}
+
+	for i, v := range signal {
+		actual := r.Push(v)
+		start := i - window + 1
+		if start < 0 {
+			start = 0
+		}
+		expected := rms(signal[start : i+1])
+		if math.Abs(actual-expected) > 1e-9+1e-6*expected {
+			t.Fatalf("Sample %d: expected RMS %g, got %g", i, expected, actual)
+		}
+	}
+}
+
+func TestRollingRMSRejectsEmptyWindow(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic for a window under one sample")
+		}
+	}()
+	NewRollingRMS(0)
+}
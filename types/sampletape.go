@@ -0,0 +1,87 @@
+// A seekable byte stream view of the float64 values held in a typed buffer.
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// bytesPerSample is how many bytes each float64 value takes within a tape.
+const bytesPerSample = 8
+
+// SampleTape reads and writes the float64 values of a buffer as little-endian bytes.
+// Positions are in bytes, relative to the least recent value currently in the buffer.
+type SampleTape struct {
+	buffer *TypedBuffer
+	at     int64
+	// Bytes written that don't yet make up a whole sample.
+	partial []byte
+}
+
+// NewSampleTape creates a tape over a buffer, positioned at the least recent value.
+func NewSampleTape(b *TypedBuffer) *SampleTape {
+	return &SampleTape{buffer: b}
+}
+
+// Read reads the bytes of values from the current position, returning io.EOF once
+// past the most recent value.
+func (t *SampleTape) Read(p []byte) (int, error) {
+	values := t.buffer.floatValues()
+	total := int64(len(values) * bytesPerSample)
+	if t.at >= total {
+		return 0, io.EOF
+	}
+
+	first := int(t.at / bytesPerSample)
+	last := first + (int(t.at%bytesPerSample)+len(p)+bytesPerSample-1)/bytesPerSample
+	if last > len(values) {
+		last = len(values)
+	}
+	encoded := make([]byte, (last-first)*bytesPerSample)
+	for i, v := range values[first:last] {
+		binary.LittleEndian.PutUint64(encoded[i*bytesPerSample:], math.Float64bits(v))
+	}
+
+	n := copy(p, encoded[t.at%bytesPerSample:])
+	t.at += int64(n)
+	return n, nil
+}
+
+// Write pushes the bytes as new values onto the end of the buffer. Bytes that don't complete a
+// value are held until a later write does. The read position is not moved, but note that it is
+// relative to the least recent value, so will refer to newer data if the buffer was full.
+func (t *SampleTape) Write(p []byte) (int, error) {
+	data := append(t.partial, p...)
+	for len(data) >= bytesPerSample {
+		t.buffer.Push(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		data = data[bytesPerSample:]
+	}
+	t.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Seek moves the position, relative to the least recent value (io.SeekStart), the current
+// position (io.SeekCurrent) or the end of the most recent value (io.SeekEnd).
+// The resulting position is clamped to within the values available.
+func (t *SampleTape) Seek(offset int64, whence int) (int64, error) {
+	total := int64(len(t.buffer.floatValues()) * bytesPerSample)
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += t.at
+	case io.SeekEnd:
+		offset += total
+	default:
+		return t.at, errors.New("Invalid seek whence")
+	}
+
+	if offset < 0 {
+		offset = 0
+	} else if offset > total {
+		offset = total
+	}
+	t.at = offset
+	return t.at, nil
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+// readSamples reads whole float64 samples from a tape until it runs out.
+func readSamples(t *testing.T, tape *SampleTape) []float64 {
+	result := []float64{}
+	sample := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(tape, sample); err == io.EOF {
+			return result
+		} else if err != nil {
+			t.Fatalf("Error reading tape: %s", err)
+		}
+		result = append(result, math.Float64frombits(binary.LittleEndian.Uint64(sample)))
+	}
+}
+
+func TestSampleTapeWriteSeekRead(t *testing.T) {
+	b := NewTypedBuffer(4)
+	tape := NewSampleTape(b)
+
+	data := make([]byte, 6*8)
+	for i := 0; i < 6; i++ {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(float64(i)/10))
+	}
+	// Write in uneven pieces, splitting samples.
+	tape.Write(data[:5])
+	tape.Write(data[5:30])
+	tape.Write(data[30:])
+	assertClose(t, []float64{0.2, 0.3, 0.4, 0.5}, b.floatValues(), 0)
+
+	assertClose(t, []float64{0.2, 0.3, 0.4, 0.5}, readSamples(t, tape), 0)
+
+	// Seek back two samples from the end.
+	if at, _ := tape.Seek(-16, io.SeekCurrent); at != 16 {
+		t.Fatalf("Expected position 16, got %d", at)
+	}
+	assertClose(t, []float64{0.4, 0.5}, readSamples(t, tape), 0)
+
+	if at, _ := tape.Seek(-8, io.SeekEnd); at != 24 {
+		t.Fatalf("Expected position 24, got %d", at)
+	}
+	assertClose(t, []float64{0.5}, readSamples(t, tape), 0)
+}
+
+func TestSampleTapeSeekClamps(t *testing.T) {
+	tape := NewSampleTape(newFloatBuffer([]float64{1, 2, 3}))
+	if at, _ := tape.Seek(-100, io.SeekStart); at != 0 {
+		t.Errorf("Expected seek clamped to 0, got %d", at)
+	}
+	if at, _ := tape.Seek(100, io.SeekStart); at != 24 {
+		t.Errorf("Expected seek clamped to 24, got %d", at)
+	}
+	if n, err := tape.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("Expected EOF past the newest sample, got %d, %v", n, err)
+	}
+
+	// Reads from partway through a sample.
+	tape.Seek(12, io.SeekStart)
+	p := make([]byte, 6)
+	if n, _ := tape.Read(p); n != 6 {
+		t.Fatalf("Expected 6 bytes read, got %d", n)
+	}
+	expected := make([]byte, 24)
+	for i, v := range []float64{1, 2, 3} {
+		binary.LittleEndian.PutUint64(expected[8*i:], math.Float64bits(v))
+	}
+	if string(p) != string(expected[12:18]) {
+		t.Errorf("Expected bytes %v, got %v", expected[12:18], p)
+	}
+}
@@ -0,0 +1,41 @@
+// Maximum over a sliding window, in amortized constant time per sample.
+package types
+
+import (
+	"fmt"
+)
+
+// SlidingMax tracks the largest of the most recent samples.
+type SlidingMax struct {
+	window int
+	pushed int
+	// Decreasing candidates for the maximum, oldest first, and where each was pushed.
+	values  []float64
+	indices []int
+}
+
+// NewSlidingMax creates a tracker over a window of the given number of samples.
+func NewSlidingMax(window int) *SlidingMax {
+	if window < 1 {
+		panic(fmt.Sprintf("NewSlidingMax window must be at least one sample, got %d", window))
+	}
+	return &SlidingMax{window: window}
+}
+
+// Push adds the next sample, and returns the maximum of the window now ending with it.
+func (m *SlidingMax) Push(sample float64) float64 {
+	// Anything older and no larger can never be the maximum again.
+	last := len(m.values)
+	for last > 0 && m.values[last-1] <= sample {
+		last--
+	}
+	m.values = append(m.values[:last], sample)
+	m.indices = append(m.indices[:last], m.pushed)
+	m.pushed++
+
+	// Drop the front once it falls out of the window.
+	if m.indices[0] <= m.pushed-1-m.window {
+		m.values, m.indices = m.values[1:], m.indices[1:]
+	}
+	return m.values[0]
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSlidingMaxMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, window := range []int{1, 2, 17, 100} {
+		m := NewSlidingMax(window)
+		signal := make([]float64, 5000)
+		for i := range signal {
+			signal[i] = r.NormFloat64()
+			if i%500 < 100 {
+				// Descending stretches, the worst case for the deque.
+				signal[i] = -float64(i % 500)
+			}
+		}
+
+		for i, v := range signal {
+			expected := math.Inf(-1)
+			for j := i; j >= 0 && j > i-window; j-- {
+				expected = math.Max(expected, signal[j])
+			}
+			if actual := m.Push(v); actual != expected {
+				t.Fatalf("Window %d, sample %d: expected max %f, got %f", window, i, expected, actual)
+			}
+		}
+	}
+}
+
+func TestSlidingMaxRejectsEmptyWindow(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic for a window under one sample")
+		}
+	}()
+	NewSlidingMax(0)
+}
@@ -0,0 +1,37 @@
+// Shared helpers for frequency domain processing of typed buffers.
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// isPowerOf2 returns whether x is a positive power of two.
+func isPowerOf2(x int) bool {
+	return x > 0 && x&(x-1) == 0
+}
+
+// fftValues returns the float64 values in the buffer ready for an FFT: least recent first,
+// zero padded up to the capacity, which must be a power of two.
+func (b *TypedBuffer) fftValues() ([]float64, error) {
+	if !isPowerOf2(b.capacity) {
+		return nil, fmt.Errorf("%w: %d is not a power of two", ErrInvalidCapacity, b.capacity)
+	}
+	values := b.floatValues()
+	padded := make([]float64, b.capacity)
+	copy(padded, values)
+	return padded, nil
+}
+
+// hannWindow returns the coefficients of a Hann window of a given length.
+func hannWindow(n int) []float64 {
+	result := make([]float64, n)
+	if n == 1 {
+		result[0] = 1.0
+		return result
+	}
+	for i := range result {
+		result[i] = 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(n-1)))
+	}
+	return result
+}
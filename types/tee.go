@@ -0,0 +1,37 @@
+// Pushing every value to two buffers at once.
+package types
+
+import (
+	"sync"
+)
+
+// TeeBuffer pushes values to two buffers, e.g. for short and long window analysis.
+type TeeBuffer struct {
+	first  *TypedBuffer
+	second *TypedBuffer
+	// Held while pushing to both, so concurrent pushes reach them in the same order.
+	lock sync.Mutex
+}
+
+// NewTee creates a tee over two buffers, which may have different capacities.
+func NewTee(b1 *TypedBuffer, b2 *TypedBuffer) *TeeBuffer {
+	return &TeeBuffer{first: b1, second: b2}
+}
+
+// Push adds a new value to the end of both buffers.
+func (t *TeeBuffer) Push(value interface{}) {
+	t.lock.Lock()
+	t.first.Push(value)
+	t.second.Push(value)
+	t.lock.Unlock()
+}
+
+// First returns the first of the buffers pushed to.
+func (t *TeeBuffer) First() *TypedBuffer {
+	return t.first
+}
+
+// Second returns the second of the buffers pushed to.
+func (t *TeeBuffer) Second() *TypedBuffer {
+	return t.second
+}
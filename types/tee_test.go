@@ -0,0 +1,38 @@
+package types
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTeeBuffer(t *testing.T) {
+	tee := NewTee(NewTypedBuffer(3), NewTypedBuffer(6))
+	for i := 0; i < 10; i++ {
+		tee.Push(float64(i))
+	}
+	assertValues(t, []float64{7, 8, 9}, tee.First().Freeze().values)
+	assertValues(t, []float64{4, 5, 6, 7, 8, 9}, tee.Second().Freeze().values)
+}
+
+func TestTeeBufferConcurrentLockstep(t *testing.T) {
+	tee := NewTee(NewTypedBuffer(50), NewTypedBuffer(200))
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			for i := 0; i < 50; i++ {
+				tee.Push(float64(1000*p + i))
+			}
+			wg.Done()
+		}(p)
+	}
+	wg.Wait()
+
+	// The short window must be exactly the end of the long one.
+	long := tee.Second().Freeze()
+	tee.First().Each(func(i int, value interface{}) {
+		if expected := long.At(150 + i); value != expected {
+			t.Fatalf("Index %d: expected %v, got %v", i, expected, value)
+		}
+	})
+}
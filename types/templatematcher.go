@@ -0,0 +1,70 @@
+// Matched filtering of a stream of samples against a fixed template.
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// TemplateMatcher scores how well the most recent samples match a template.
+type TemplateMatcher struct {
+	// Template with its mean removed, and the norm of that.
+	template []float64
+	norm     float64
+	recent   *Buffer
+}
+
+// NewTemplateMatcher creates a matcher for the given template of samples.
+func NewTemplateMatcher(template []float64) *TemplateMatcher {
+	if len(template) < 1 {
+		panic(fmt.Sprintf("NewTemplateMatcher template must have at least one sample, got %d", len(template)))
+	}
+	centred, norm := centre(template)
+	return &TemplateMatcher{
+		centred,
+		norm,
+		NewBuffer(len(template)),
+	}
+}
+
+// Push adds the next sample, and returns the normalized cross correlation between
+// the template and the most recent samples: 1 for a perfect match, -1 for an inverted
+// one. Zero is returned until enough samples have been seen.
+func (m *TemplateMatcher) Push(sample float64) float64 {
+	m.recent.Push(sample)
+	if !m.recent.IsFull() || m.norm == 0 {
+		return 0.0
+	}
+
+	window := make([]float64, 0, len(m.template))
+	m.recent.Each(func(i int, value float64) {
+		window = append(window, value)
+	})
+	window, norm := centre(window)
+	if norm == 0 {
+		return 0.0
+	}
+
+	score := 0.0
+	for i, v := range window {
+		score += v * m.template[i]
+	}
+	return score / (norm * m.norm)
+}
+
+// centre returns a copy of values with their mean removed, plus the norm of the result.
+func centre(values []float64) ([]float64, float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	result := make([]float64, len(values))
+	sumSquares := 0.0
+	for i, v := range values {
+		result[i] = v - mean
+		sumSquares += result[i] * result[i]
+	}
+	return result, math.Sqrt(sumSquares)
+}
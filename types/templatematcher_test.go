@@ -0,0 +1,56 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTemplateMatcherPeaksWhenAligned(t *testing.T) {
+	template := []float64{0, 0.5, 1, 0.5, 0, -0.5, -1, -0.5, 0.25, 0.75}
+	r := rand.New(rand.NewSource(1))
+
+	offset := 137
+	signal := make([]float64, 400)
+	for i := range signal {
+		signal[i] = 0.2 * (r.Float64() - 0.5)
+	}
+	for i, v := range template {
+		signal[offset+i] += v
+	}
+
+	m := NewTemplateMatcher(template)
+	best, bestAt := math.Inf(-1), -1
+	for i, v := range signal {
+		if score := m.Push(v); score > best {
+			best, bestAt = score, i
+		}
+	}
+
+	// The match completes at the last sample of the template.
+	if expected := offset + len(template) - 1; bestAt != expected {
+		t.Errorf("Expected best match at %d, got %d", expected, bestAt)
+	}
+	if best < 0.9 || best > 1.0+1e-12 {
+		t.Errorf("Expected a best score near 1, got %f", best)
+	}
+}
+
+func TestTemplateMatcherNotFull(t *testing.T) {
+	m := NewTemplateMatcher([]float64{1, 2, 3})
+	if m.Push(1) != 0 || m.Push(2) != 0 {
+		t.Fatalf("Expected zero score before the window fills")
+	}
+	if score := m.Push(3); math.Abs(score-1.0) > 1e-12 {
+		t.Fatalf("Expected exact match score 1, got %f", score)
+	}
+}
+
+func TestTemplateMatcherRejectsEmptyTemplate(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected a panic for an empty template")
+		}
+	}()
+	NewTemplateMatcher(nil)
+}
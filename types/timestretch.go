@@ -0,0 +1,91 @@
+// Time stretching with a phase vocoder.
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/mjibson/go-dsp/fft"
+)
+
+// TimeStretch returns the float64 values in the buffer slowed down (factor > 1) or sped up
+// (factor < 1) without changing pitch, and with length scaled by factor. This is a basic phase
+// vocoder: Hann windowed frames of frameSize (a power of two) are taken every hop samples, have
+// their phases advanced to match the new hop of hop * factor, and are overlap-added back.
+func (b *TypedBuffer) TimeStretch(factor float64, frameSize int, hop int) ([]float64, error) {
+	if factor <= 0 {
+		return nil, errors.New("TimeStretch factor must be positive")
+	}
+	if !isPowerOf2(frameSize) {
+		return nil, fmt.Errorf("%w: frame size %d is not a power of two", ErrInvalidCapacity, frameSize)
+	}
+	if hop < 1 || hop > frameSize {
+		return nil, errors.New("TimeStretch hop must be between 1 and the frame size")
+	}
+
+	values := b.floatValues()
+	outputLength := int(math.Floor(float64(len(values))*factor + 0.5))
+	if len(values) < frameSize {
+		values = append(values, make([]float64, frameSize-len(values))...)
+	}
+	frames := 1 + (len(values)-frameSize)/hop
+	synthesisHop := float64(hop) * factor
+
+	window := hannWindow(frameSize)
+	bins := frameSize/2 + 1
+	lastPhase := make([]float64, bins)
+	phase := make([]float64, bins)
+
+	length := int(float64(frames-1)*synthesisHop) + frameSize
+	if length < outputLength {
+		length = outputLength
+	}
+	output := make([]float64, length)
+	windowSum := make([]float64, length)
+
+	frame := make([]float64, frameSize)
+	spectrum := make([]complex128, frameSize)
+	for f := 0; f < frames; f++ {
+		for i := range frame {
+			frame[i] = values[f*hop+i] * window[i]
+		}
+		analysed := fft.FFTReal(frame)
+
+		for k := 0; k < bins; k++ {
+			magnitude, p := cmplx.Polar(analysed[k])
+			if f == 0 {
+				phase[k] = p
+			} else {
+				// Deviation from the phase advance expected of the bin's centre frequency.
+				expected := 2 * math.Pi * float64(k*hop) / float64(frameSize)
+				deviation := wrapPhase(p - lastPhase[k] - expected)
+				phase[k] += (expected + deviation) * factor
+			}
+			lastPhase[k] = p
+			spectrum[k] = cmplx.Rect(magnitude, phase[k])
+			if k > 0 && k < frameSize-k {
+				spectrum[frameSize-k] = cmplx.Conj(spectrum[k])
+			}
+		}
+
+		start := int(float64(f) * synthesisHop)
+		for i, c := range fft.IFFT(spectrum) {
+			output[start+i] += real(c) * window[i]
+			windowSum[start+i] += window[i] * window[i]
+		}
+	}
+
+	for i := range output {
+		if windowSum[i] > 1e-6 {
+			output[i] /= windowSum[i]
+		}
+	}
+	return output[:outputLength], nil
+}
+
+// wrapPhase brings a phase into [-pi, pi).
+func wrapPhase(p float64) float64 {
+	return p - 2*math.Pi*math.Floor((p+math.Pi)/(2*math.Pi))
+}
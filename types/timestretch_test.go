@@ -0,0 +1,54 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+// zeroCrossingRate returns the fraction of adjacent sample pairs that change sign.
+func zeroCrossingRate(values []float64) float64 {
+	crossings := 0
+	for i := 1; i < len(values); i++ {
+		if (values[i-1] < 0) != (values[i] < 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(values)-1)
+}
+
+func TestTimeStretchPreservesPitch(t *testing.T) {
+	b := sineBuffer(0.5, 440, 44100, 16384)
+	inputRate := zeroCrossingRate(b.floatValues())
+
+	for _, factor := range []float64{0.5, 1.5, 2} {
+		stretched, err := b.TimeStretch(factor, 1024, 256)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if expected := int(16384*factor + 0.5); len(stretched) != expected {
+			t.Errorf("Factor %f: expected %d samples, got %d", factor, expected, len(stretched))
+		}
+
+		// Ignore the edges, where frames only partly overlap.
+		middle := stretched[len(stretched)/8 : len(stretched)*7/8]
+		if rate := zeroCrossingRate(middle); math.Abs(rate-inputRate) > 0.05*inputRate {
+			t.Errorf("Factor %f: expected zero crossing rate %f, got %f", factor, inputRate, rate)
+		}
+		if level := rms(middle); math.Abs(level-rms(b.floatValues())) > 0.1 {
+			t.Errorf("Factor %f: expected level to be kept, got RMS %f", factor, level)
+		}
+	}
+}
+
+func TestTimeStretchInvalid(t *testing.T) {
+	b := sineBuffer(0.5, 440, 44100, 2048)
+	if _, err := b.TimeStretch(1.5, 1000, 250); err == nil {
+		t.Errorf("Expected error for a non power of two frame size")
+	}
+	if _, err := b.TimeStretch(0, 1024, 256); err == nil {
+		t.Errorf("Expected error for a zero factor")
+	}
+	if _, err := b.TimeStretch(1, 1024, 0); err == nil {
+		t.Errorf("Expected error for a zero hop")
+	}
+}
@@ -0,0 +1,35 @@
+// Trimming silence from the ends of the float64 values held in a typed buffer.
+package types
+
+import (
+	"math"
+)
+
+// TrimSilence returns the float64 values in the buffer, least recent first, without any leading
+// or trailing silence. The values are split into frames of frameSize (the last may be shorter),
+// and frames with an RMS below thresholdRMS are dropped from either end. Quiet frames between
+// louder ones are kept. If every frame is quiet, the result is empty.
+func (b *TypedBuffer) TrimSilence(thresholdRMS float64, frameSize int) []float64 {
+	if frameSize < 1 {
+		panic("TrimSilence frame size must be positive")
+	}
+	values := b.floatValues()
+
+	start, end := -1, 0
+	for frameStart := 0; frameStart < len(values); frameStart += frameSize {
+		frameEnd := frameStart + frameSize
+		if frameEnd > len(values) {
+			frameEnd = len(values)
+		}
+		if math.Sqrt(meanSquare(values[frameStart:frameEnd])) >= thresholdRMS {
+			if start < 0 {
+				start = frameStart
+			}
+			end = frameEnd
+		}
+	}
+	if start < 0 {
+		return []float64{}
+	}
+	return values[start:end]
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestTrimSilence(t *testing.T) {
+	// 250 silent, 100 loud, 100 silent, 100 loud, 300 near silent.
+	values := make([]float64, 850)
+	for i := 250; i < 350; i++ {
+		values[i] = 0.5
+	}
+	for i := 450; i < 550; i++ {
+		values[i] = -0.5
+	}
+	for i := 550; i < len(values); i++ {
+		values[i] = 0.001
+	}
+
+	actual := newFloatBuffer(values).TrimSilence(0.01, 50)
+	// Loud frames are [250, 350) and [450, 550), and the quiet gap between them is kept.
+	if len(actual) != 300 {
+		t.Fatalf("Expected 300 values, got %d", len(actual))
+	}
+	assertClose(t, values[250:550], actual, 0)
+
+	// Frames that straddle the edges are kept whole.
+	actual = newFloatBuffer(values).TrimSilence(0.01, 100)
+	assertClose(t, values[200:600], actual, 0)
+}
+
+func TestTrimSilenceShortLastFrame(t *testing.T) {
+	values := []float64{0, 0, 0, 0, 1}
+	assertClose(t, []float64{1}, newFloatBuffer(values).TrimSilence(0.5, 2), 0)
+}
+
+func TestTrimSilenceAllSilent(t *testing.T) {
+	if actual := constantBuffer(0.001, 1000).TrimSilence(0.01, 64); len(actual) != 0 {
+		t.Errorf("Expected nothing left, got %d values", len(actual))
+	}
+	if actual := NewTypedBuffer(10).TrimSilence(0.01, 64); len(actual) != 0 {
+		t.Errorf("Expected nothing from an empty buffer, got %d values", len(actual))
+	}
+}
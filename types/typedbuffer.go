@@ -2,7 +2,20 @@
 package types
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
 	"sync"
+	"time"
+)
+
+const (
+	// fullEventsBacklog is how many unreceived full events are kept before new ones are dropped.
+	fullEventsBacklog = 16
+	// occupancyBins is how many bins the fill fraction histogram has.
+	occupancyBins = 10
 )
 
 // Buffer holds the values within the buffer plus a collection of metadata.
@@ -13,31 +26,271 @@ type TypedBuffer struct {
 	at       int
 	lock     sync.Mutex
 	finished bool
+
+	// Signalled whenever values are pushed or popped, for the blocking calls to wait on.
+	changed *sync.Cond
+
+	// Whether to skip locking, for buffers only ever used from one thread.
+	noLock bool
+	// Whether values is storage borrowed from an Arena.
+	fromArena bool
+	// The kind of value all pushes must be, or reflect.Invalid if any are allowed.
+	kind reflect.Kind
+	// Whether float64 helpers ignore NaN and infinite values.
+	skipInvalid bool
+
+	// Pushes since the buffer last became full, and where to notify when it does.
+	sinceFull  int
+	fullEvents chan struct{}
+	// Where to report unread values being overwritten.
+	overrunEvents chan int
+
+	// When the most recent value was pushed, according to nowFunc.
+	lastPush time.Time
+	nowFunc  func() time.Time
+
+	// How many of the most recent values are yet to be consumed,
+	// and how many were overwritten before they could be.
+	unread int
+	lost   uint64
+
+	// Running totals of values pushed, and pushed out. These wrap around at 2^64.
+	pushed  uint64
+	evicted uint64
+
+	// Where to stream fixed size chunks, and how many values are waiting to be streamed.
+	chunkSize    int
+	chunkSink    func([]float64)
+	chunkPending int
+
+	// Counts of how full the buffer was after each push, if tracked.
+	occupancy []int
+
+	// Samples per second of the values, or 0 if not known.
+	sampleRate int
+
+	// Called with the old and new capacities after each Resize.
+	onResize func(oldCapacity, newCapacity int)
+
+	// Weight of each value, in the same positions as values.
+	// Left nil until a weight other than 1 is pushed.
+	weights []float64
 }
 
 // NewTypedBuffer creates a new circular buffer of a given maximum size.
+// A capacity of zero is allowed, and gives a buffer that keeps no history:
+// it is always full, always empty, and each value pushed is immediately evicted.
+// Negative capacities panic, and the whole capacity is allocated up front,
+// so absurdly large ones will fail by running out of memory.
 func NewTypedBuffer(capacity int) *TypedBuffer {
+	if capacity < 0 {
+		panic(fmt.Sprintf("NewTypedBuffer capacity must not be negative, got %d", capacity))
+	}
+	return newTypedBuffer(make([]interface{}, capacity))
+}
+
+// newTypedBuffer creates a new circular buffer using the given slice for its values,
+// with a capacity of its length.
+func newTypedBuffer(values []interface{}) *TypedBuffer {
 	b := TypedBuffer{
-		make([]interface{}, capacity),
-		capacity,
-		0, /* size */
-		0, /* at */
-		sync.Mutex{},
-		false, /* finished */
+		values:   values,
+		capacity: len(values),
+		nowFunc:  time.Now,
 	}
+	b.changed = sync.NewCond(&b.lock)
 	return &b
 }
 
+// TryNewTypedBuffer is NewTypedBuffer, returning ErrInvalidCapacity rather than panicking.
+func TryNewTypedBuffer(capacity int) (*TypedBuffer, error) {
+	if capacity < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	return NewTypedBuffer(capacity), nil
+}
+
+// NewStrictTypedBuffer creates a new circular buffer of a given maximum size,
+// that only accepts values of the given kind. Pushing any other kind panics.
+func NewStrictTypedBuffer(capacity int, kind reflect.Kind) *TypedBuffer {
+	b := NewTypedBuffer(capacity)
+	b.kind = kind
+	return b
+}
+
+// ElementKind returns the kind of value a strict buffer accepts,
+// or reflect.Invalid for buffers accepting anything.
+func (b *TypedBuffer) ElementKind() reflect.Kind {
+	return b.kind
+}
+
+// checkKind panics if the buffer is strict and the value is of the wrong kind.
+func (b *TypedBuffer) checkKind(value interface{}) {
+	if b.kind != reflect.Invalid && reflect.ValueOf(value).Kind() != b.kind {
+		panic(fmt.Sprintf("%s: pushed %T to a buffer of %s", ErrTypeMismatch, value, b.kind))
+	}
+}
+
+// NewTypedBufferUnsafe creates a new circular buffer of a given maximum size that never locks.
+// This removes the locking overhead in single threaded code, but the buffer is NOT safe
+// for concurrent use: all calls must come from the same thread, and while a GoPushChannel
+// goroutine is running, nothing else may touch the buffer.
+func NewTypedBufferUnsafe(capacity int) *TypedBuffer {
+	b := NewTypedBuffer(capacity)
+	b.noLock = true
+	return b
+}
+
+// lockBuffer acquires the buffer lock, unless the buffer is unsafe.
+func (b *TypedBuffer) lockBuffer() {
+	if !b.noLock {
+		b.lock.Lock()
+	}
+}
+
+// unlockBuffer releases the buffer lock, unless the buffer is unsafe.
+func (b *TypedBuffer) unlockBuffer() {
+	if !b.noLock {
+		b.lock.Unlock()
+	}
+}
+
 // Push adds a new value at the end of the buffer.
 func (b *TypedBuffer) Push(value interface{}) interface{} {
+	b.checkKind(value)
+	b.lockBuffer()
+	result, stream := b.pushLocked(value)
+	b.unlockBuffer()
+	if stream != nil {
+		stream()
+	}
+	return result
+}
+
+// PushBlocking is Push, but waits until there is space rather than overwriting old values,
+// returning the context error if it is done first. Space is made by PopBlocking.
+// The blocking calls always lock, so mustn't be used with NewTypedBufferUnsafe buffers.
+func (b *TypedBuffer) PushBlocking(ctx context.Context, value interface{}) error {
+	b.checkKind(value)
+	stop := context.AfterFunc(ctx, b.wakeAll)
+	defer stop()
+
+	b.lock.Lock()
+	for b.capacity > 0 && b.size == b.capacity {
+		if err := ctx.Err(); err != nil {
+			b.lock.Unlock()
+			return err
+		}
+		b.changed.Wait()
+	}
+	_, stream := b.pushLocked(value)
+	b.lock.Unlock()
+	if stream != nil {
+		stream()
+	}
+	return nil
+}
+
+// PopBlocking removes and returns the least recent value, waiting until there is one,
+// or returning the context error if it is done first.
+func (b *TypedBuffer) PopBlocking(ctx context.Context) (interface{}, error) {
+	stop := context.AfterFunc(ctx, b.wakeAll)
+	defer stop()
+
 	b.lock.Lock()
+	defer b.lock.Unlock()
+	for b.size == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b.changed.Wait()
+	}
+
+	value := b.values[b.physicalIndex(0)]
+	b.size--
+	if b.unread > b.size {
+		b.unread = b.size
+	}
+	b.clampChunkPendingLocked()
+	b.changed.Broadcast()
+	return value, nil
+}
+
+// wakeAll wakes all blocking calls, so they can check whether their context is done.
+func (b *TypedBuffer) wakeAll() {
+	b.lock.Lock()
+	b.changed.Broadcast()
+	b.lock.Unlock()
+}
+
+// PushUnique pushes a value only if it differs from the most recent one, returning whether it did.
+// Values that can't be compared with == (like slices) are always pushed.
+func (b *TypedBuffer) PushUnique(value interface{}) bool {
+	b.checkKind(value)
+	b.lockBuffer()
+	if b.size > 0 {
+		newest := b.values[b.physicalIndex(b.size-1)]
+		if isComparable(value) && isComparable(newest) && value == newest {
+			b.unlockBuffer()
+			return false
+		}
+	}
+	_, stream := b.pushLocked(value)
+	b.unlockBuffer()
+	if stream != nil {
+		stream()
+	}
+	return true
+}
+
+// isComparable returns whether a value can be used with == without panicking.
+func isComparable(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).Comparable()
+}
+
+// pushLocked is Push for callers holding the lock. If the push completes a chunk to stream,
+// the function to stream it is also returned, to be called once the lock is released.
+func (b *TypedBuffer) pushLocked(value interface{}) (interface{}, func()) {
+	return b.pushWeightedLocked(value, 1.0)
+}
+
+// pushWeightedLocked is pushLocked, also recording the weight of the value.
+func (b *TypedBuffer) pushWeightedLocked(value interface{}, weight float64) (interface{}, func()) {
+	b.changed.Broadcast()
+	b.lastPush = b.nowFunc()
+	b.pushed++
+	if b.capacity == 0 {
+		// Nowhere to keep it, so it is evicted straight away.
+		b.evicted++
+		return value, nil
+	}
 
 	result := b.values[b.at]
 	b.values[b.at] = value
+	if b.weights != nil {
+		b.weights[b.at] = weight
+	}
 
 	if b.size < b.capacity {
 		b.size++
 		result = 0.0
+	} else {
+		b.evicted++
+	}
+	if b.unread < b.size {
+		b.unread++
+	} else {
+		b.lost++
+		if b.overrunEvents != nil {
+			count := 1
+			select {
+			case waiting := <-b.overrunEvents:
+				// The last event hasn't been received, so fold it into this one.
+				count += waiting
+			default:
+			}
+			// Only pushes send, under the lock, so after the receive above this never blocks.
+			b.overrunEvents <- count
+		}
 	}
 
 	if b.at+1 < b.capacity {
@@ -46,52 +299,479 @@ func (b *TypedBuffer) Push(value interface{}) interface{} {
 		b.at = 0
 	}
 
-	b.lock.Unlock()
-	return result
+	b.sinceFull++
+	if b.sinceFull >= b.capacity {
+		b.sinceFull = 0
+		if b.fullEvents != nil {
+			select {
+			case b.fullEvents <- struct{}{}:
+			default:
+				// Nobody is listening, so drop the event rather than block the producer.
+			}
+		}
+	}
+
+	if b.occupancy != nil {
+		// Bin i counts fill fractions within (i / bins, (i + 1) / bins]
+		bin := (b.size*occupancyBins+b.capacity-1)/b.capacity - 1
+		b.occupancy[bin]++
+	}
+
+	return result, b.completedChunkLocked()
+}
+
+// EnableOccupancyTracking starts recording how full the buffer is after each push,
+// to help choose a capacity. See OccupancyHistogram.
+func (b *TypedBuffer) EnableOccupancyTracking() {
+	b.lockBuffer()
+	if b.occupancy == nil {
+		b.occupancy = make([]int, occupancyBins)
+	}
+	b.unlockBuffer()
+}
+
+// OccupancyHistogram returns how many pushes left the buffer within each range of fullness.
+// Bin i counts pushes after which the buffer was more than i/10 and at most (i+1)/10 full.
+// This is nil unless EnableOccupancyTracking has been called.
+func (b *TypedBuffer) OccupancyHistogram() []int {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.occupancy == nil {
+		return nil
+	}
+	return append([]int(nil), b.occupancy...)
+}
+
+// StreamChunks calls sink with each new block of chunkSize float64 values as they are pushed,
+// least recent first, with no overlap or gaps between blocks. Values of other types are
+// streamed as zero. Each chunk is copied out by the Push that completes it, so chunks are
+// never dropped however fast values arrive, but the sink runs on the pushing thread and
+// should not itself push to the buffer. chunkSize must be between 1 and the capacity,
+// and a nil sink stops streaming.
+func (b *TypedBuffer) StreamChunks(chunkSize int, sink func([]float64)) error {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if sink != nil && (chunkSize < 1 || chunkSize > b.capacity) {
+		return fmt.Errorf("%w: chunk size %d must be between 1 and %d", ErrInvalidCapacity, chunkSize, b.capacity)
+	}
+	b.chunkSize = chunkSize
+	b.chunkSink = sink
+	b.chunkPending = 0
+	return nil
+}
+
+// clampChunkPendingLocked makes sure the values waiting to be streamed are all still held,
+// after the buffer loses some of its least recent ones. Any that are gone were taken out of
+// the buffer before they could be streamed, and the next chunk mustn't reach past the rest.
+func (b *TypedBuffer) clampChunkPendingLocked() {
+	if b.chunkPending > b.size {
+		b.chunkPending = b.size
+	}
+}
+
+// completedChunkLocked returns a function streaming the latest chunk, if the last push completed one.
+func (b *TypedBuffer) completedChunkLocked() func() {
+	if b.chunkSink == nil {
+		return nil
+	}
+	b.chunkPending++
+	if b.chunkPending < b.chunkSize {
+		return nil
+	}
+	b.chunkPending = 0
+
+	chunk := make([]float64, b.chunkSize)
+	for i := range chunk {
+		chunk[i], _ = b.values[b.physicalIndex(b.size-b.chunkSize+i)].(float64)
+	}
+	sink := b.chunkSink
+	return func() {
+		sink(chunk)
+	}
+}
+
+// FullEvents returns a channel that receives an event each time the buffer becomes full,
+// and again every time another capacity worth of values has been pushed since.
+// Sends never block Push: up to fullEventsBacklog events are kept for a slow receiver,
+// after which new events are dropped until some are received.
+func (b *TypedBuffer) FullEvents() <-chan struct{} {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.fullEvents == nil {
+		b.fullEvents = make(chan struct{}, fullEventsBacklog)
+	}
+	return b.fullEvents
+}
+
+// OverrunEvents returns a channel that receives, whenever a push overwrites a value not yet
+// returned by ConsumeN, how many unread values have been lost. Sends never block Push:
+// while an event is waiting to be received, further losses are added to it instead,
+// so the counts received always add up to the number of values lost.
+func (b *TypedBuffer) OverrunEvents() <-chan int {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.overrunEvents == nil {
+		b.overrunEvents = make(chan int, 1)
+	}
+	return b.overrunEvents
 }
 
 // GoPushChannel constantly pushes values from a channel, in a separate thread,
 // optionally only sampling 1 every sampleRate values.
 func (b *TypedBuffer) GoPushChannel(values <-chan interface{}, sampleRate int) {
+	b.setFinished(false)
+	go func() {
+		b.pushChannel(values, sampleRate)
+		b.setFinished(true)
+	}()
+}
+
+// GoPushMerge is GoPushChannel for multiple channels at once, each pushed from its own thread.
+// The buffer is only finished once every channel has been closed.
+func (b *TypedBuffer) GoPushMerge(sources []<-chan interface{}, sampleRate int) {
+	b.setFinished(false)
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for _, source := range sources {
+		go func(values <-chan interface{}) {
+			b.pushChannel(values, sampleRate)
+			wg.Done()
+		}(source)
+	}
+	go func() {
+		wg.Wait()
+		b.setFinished(true)
+	}()
+}
+
+// pushChannel pushes values from a channel until it is closed,
+// only sampling 1 every sampleRate values.
+func (b *TypedBuffer) pushChannel(values <-chan interface{}, sampleRate int) {
 	var val interface{}
 	ok := true
-	b.finished = false
-	go func() {
-		for {
-			if val, ok = <-values; !ok {
+	for {
+		if val, ok = <-values; !ok {
+			break
+		}
+		b.Push(val)
+		for i := 1; i < sampleRate; i++ {
+			if _, ok = <-values; !ok {
 				break
 			}
-			b.Push(val)
-			for i := 1; i < sampleRate; i++ {
-				if _, ok = <-values; !ok {
-					break
-				}
-			}
 		}
-		b.finished = true
-	}()
+	}
 }
 
 // GetFromEnd returns the most recent buffer values.
-// 0 returns the most recently pushed, the least recent being b.size - 1
+// 0 returns the most recently pushed, the least recent being b.size - 1.
+// Negative indices count from the other end instead, so -1 is the least recent
+// and -b.size the most recent.
 func (b *TypedBuffer) GetFromEnd(index int) interface{} {
-	b.lock.Lock()
-	defer b.lock.Unlock()
-	if index < 0 || index >= b.capacity {
+	result, err := b.TryGetFromEnd(index)
+	if err != nil {
 		panic("GetFromEnd index out of range")
+	}
+	return result
+}
+
+// TryGetFromEnd is GetFromEnd, returning ErrIndexOutOfRange rather than panicking.
+func (b *TypedBuffer) TryGetFromEnd(index int) (interface{}, error) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if index < 0 {
+		if index < -b.size {
+			return nil, ErrIndexOutOfRange
+		}
+		return b.values[b.physicalIndex(-1-index)], nil
+	}
+	if b.capacity == 0 {
+		// Nothing is ever kept, so everything is the default.
+		return 0.0, nil
+	} else if index >= b.capacity {
+		return nil, ErrIndexOutOfRange
 	} else if index >= b.size {
 		// Within range, just not filled yet, to default to zero.
-		return 0.0
+		return 0.0, nil
 	}
+	return b.values[b.physicalIndex(b.size-1-index)], nil
+}
 
-	index = b.at - index
-	if index < 0 {
-		index = index + b.capacity
+// TryGetFromEndFloat64 is TryGetFromEnd for float64 values,
+// returning ErrTypeMismatch if the value is of a different type.
+func (b *TypedBuffer) TryGetFromEndFloat64(index int) (float64, error) {
+	value, err := b.TryGetFromEnd(index)
+	if err != nil {
+		return 0.0, err
+	}
+	f, ok := value.(float64)
+	if !ok {
+		return 0.0, ErrTypeMismatch
+	}
+	return f, nil
+}
+
+// GetRange returns the values at indices [start, end) in the buffer, with 0 the least recent.
+// ErrIndexOutOfRange is returned if the range isn't within the values held.
+func (b *TypedBuffer) GetRange(start int, end int) ([]interface{}, error) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if start < 0 || end > b.size || start > end {
+		return nil, fmt.Errorf("%w: range [%d, %d) with size %d", ErrIndexOutOfRange, start, end, b.size)
+	}
+	result := make([]interface{}, end-start)
+	for i := range result {
+		result[i] = b.values[b.physicalIndex(start+i)]
+	}
+	return result, nil
+}
+
+// TryNewest returns the most recently pushed value, or ErrEmptyBuffer if there are none.
+func (b *TypedBuffer) TryNewest() (interface{}, error) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.size == 0 {
+		return nil, ErrEmptyBuffer
+	}
+	return b.values[b.physicalIndex(b.size-1)], nil
+}
+
+// Concat creates a new buffer, exactly big enough to hold the values of a followed by those of b.
+// Each input is locked only while it is copied, so passing the same buffer twice is safe.
+func Concat(a *TypedBuffer, b *TypedBuffer) *TypedBuffer {
+	a.lockBuffer()
+	first := a.snapshotLocked()
+	a.unlockBuffer()
+	b.lockBuffer()
+	second := b.snapshotLocked()
+	b.unlockBuffer()
+
+	result := NewTypedBuffer(len(first) + len(second))
+	for _, value := range first {
+		result.Push(value)
+	}
+	for _, value := range second {
+		result.Push(value)
 	}
-	result := b.values[index]
 	return result
 }
 
+// LastPushTime returns when a value was last pushed, or the zero time if never.
+func (b *TypedBuffer) LastPushTime() time.Time {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.lastPush
+}
+
+// IsStale returns whether nothing has been pushed within the given duration.
+// A buffer that has never been pushed to is always stale.
+func (b *TypedBuffer) IsStale(d time.Duration) bool {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.nowFunc().Sub(b.lastPush) > d
+}
+
+// SetClock replaces how the buffer tells the time, which is time.Now by default.
+// This lets tests control time rather than sleeping.
+func (b *TypedBuffer) SetClock(now func() time.Time) {
+	b.lockBuffer()
+	b.nowFunc = now
+	b.unlockBuffer()
+}
+
+// ConsumeN returns up to n of the oldest values not yet consumed, least recent first,
+// and marks them as consumed. Also returned is how many unconsumed values were
+// overwritten by pushes since the last call, and so never seen by the consumer.
+func (b *TypedBuffer) ConsumeN(n int) ([]interface{}, uint64) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+
+	if n > b.unread {
+		n = b.unread
+	}
+	if n < 0 {
+		n = 0
+	}
+	start := b.size - b.unread
+	result := b.snapshotLocked()[start : start+n]
+	b.unread -= n
+
+	lost := b.lost
+	b.lost = 0
+	return result, lost
+}
+
+// Pushed returns how many values have been pushed since creation or the last ResetCounters.
+func (b *TypedBuffer) Pushed() uint64 {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.pushed
+}
+
+// Evicted returns how many values have been pushed out of the buffer by newer ones,
+// since creation or the last ResetCounters.
+func (b *TypedBuffer) Evicted() uint64 {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.evicted
+}
+
+// ResetCounters zeros the pushed, evicted and lost counts, leaving the values untouched.
+func (b *TypedBuffer) ResetCounters() {
+	b.lockBuffer()
+	b.pushed = 0
+	b.evicted = 0
+	b.lost = 0
+	b.unlockBuffer()
+}
+
+// Compact rearranges the values in memory so that the least recent is first,
+// without changing anything observable through the rest of the API.
+func (b *TypedBuffer) Compact() {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	if b.weights != nil {
+		weights := make([]float64, b.size)
+		for i := range weights {
+			weights[i] = b.weights[b.physicalIndex(i)]
+		}
+		copy(b.weights, weights)
+	}
+	copy(b.values, b.snapshotLocked())
+	if b.capacity > 0 {
+		b.at = b.size % b.capacity
+	}
+}
+
+// Resize changes the capacity of the buffer, keeping as many of the most recent values as fit.
+// Resizing to the current capacity does nothing.
+// Values are moved to newly allocated storage, so a buffer from an Arena leaves it.
+// Chunk streaming stops if the chunk size no longer fits in the new capacity.
+func (b *TypedBuffer) Resize(newCapacity int) error {
+	if newCapacity < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidCapacity, newCapacity)
+	}
+	b.lockBuffer()
+	oldCapacity := b.capacity
+	if newCapacity == oldCapacity {
+		b.unlockBuffer()
+		return nil
+	}
+	keep := b.size
+	if keep > newCapacity {
+		keep = newCapacity
+	}
+	first := b.size - keep
+
+	values := make([]interface{}, newCapacity)
+	for i := range values[:keep] {
+		values[i] = b.values[b.physicalIndex(first+i)]
+	}
+	if b.weights != nil {
+		weights := make([]float64, newCapacity)
+		for i := range weights {
+			weights[i] = 1.0
+		}
+		for i := range weights[:keep] {
+			weights[i] = b.weights[b.physicalIndex(first+i)]
+		}
+		b.weights = weights
+	}
+
+	b.values = values
+	b.fromArena = false
+	b.capacity = newCapacity
+	b.size = keep
+	b.at = 0
+	if newCapacity > 0 {
+		b.at = keep % newCapacity
+	}
+	if b.unread > keep {
+		b.unread = keep
+	}
+	// Count towards the next full event as if the kept values had just been pushed.
+	b.sinceFull = keep
+	if keep == newCapacity {
+		b.sinceFull = 0
+	}
+	if b.chunkSize > newCapacity {
+		b.chunkSink = nil
+	}
+	b.clampChunkPendingLocked()
+	// Any blocked pushes may now have room.
+	b.changed.Broadcast()
+	onResize := b.onResize
+	b.unlockBuffer()
+
+	if onResize != nil {
+		onResize(oldCapacity, newCapacity)
+	}
+	return nil
+}
+
+// Swap atomically replaces the contents of the buffer with values, least recent first, and
+// returns the previous contents in the same order. If there are more values than the capacity,
+// only the most recent are kept. The new values all count as unread, with a weight of 1,
+// but aren't counted as pushed. For a strict buffer, any value of the wrong kind panics
+// as Push would, before anything is changed.
+func (b *TypedBuffer) Swap(values []interface{}) []interface{} {
+	for _, value := range values {
+		b.checkKind(value)
+	}
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	old := b.snapshotLocked()
+
+	if len(values) > b.capacity {
+		values = values[len(values)-b.capacity:]
+	}
+	copy(b.values, values)
+	// Drop references to the old values, so they can be garbage collected.
+	for i := len(values); i < b.capacity; i++ {
+		b.values[i] = nil
+	}
+	if b.weights != nil {
+		for i := range b.weights {
+			b.weights[i] = 1.0
+		}
+	}
+	b.size = len(values)
+	b.at = 0
+	if b.capacity > 0 {
+		b.at = b.size % b.capacity
+	}
+	b.unread = b.size
+	b.sinceFull = b.size
+	if b.size == b.capacity {
+		b.sinceFull = 0
+	}
+	b.chunkPending = 0
+	b.changed.Broadcast()
+	return old
+}
+
+// SetOnResize sets a function to be called with the old and new capacities each time Resize
+// changes the capacity. It runs after the resize is complete, without the buffer locked,
+// so is free to read the buffer. Pass nil to stop being notified.
+func (b *TypedBuffer) SetOnResize(onResize func(oldCapacity, newCapacity int)) {
+	b.lockBuffer()
+	b.onResize = onResize
+	b.unlockBuffer()
+}
+
+// SetSampleRate records how many samples per second the values in the buffer represent.
+func (b *TypedBuffer) SetSampleRate(sampleRate int) {
+	b.lockBuffer()
+	b.sampleRate = sampleRate
+	b.unlockBuffer()
+}
+
+// SampleRate returns the samples per second set by SetSampleRate, or 0 if never set.
+func (b *TypedBuffer) SampleRate() int {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.sampleRate
+}
+
 // IsFull returns whether the buffer is full,
 // in that adding more entries will delete older ones.
 func (b *TypedBuffer) IsFull() bool {
@@ -105,36 +785,163 @@ func (b *TypedBuffer) Size() int {
 
 // IsFinished returns whether there is nothing more to be added to the buffer
 func (b *TypedBuffer) IsFinished() bool {
+	b.lockBuffer()
+	defer b.unlockBuffer()
 	return b.finished
 }
 
+// setFinished updates whether there is nothing more to be added to the buffer.
+func (b *TypedBuffer) setFinished(finished bool) {
+	b.lockBuffer()
+	b.finished = finished
+	b.unlockBuffer()
+}
+
 // Clear resets the buffer to being empty
 func (b *TypedBuffer) Clear() {
 	// Simply clamp the size back to zero, don't worry about the existing values.
-	b.lock.Lock()
+	b.lockBuffer()
 	b.size = 0
-	b.lock.Unlock()
+	b.sinceFull = 0
+	b.unread = 0
+	b.chunkPending = 0
+	b.unlockBuffer()
 }
 
 // Each applies a given function to all the values in the buffer,
 // from least recent first, ending at the most recent.
 func (b *TypedBuffer) Each(cb func(int, interface{})) {
-	b.lock.Lock()
-	i := 0
-	if !b.IsFull() {
-		for i = 0; i < b.size; i++ {
-			cb(i, b.values[i])
+	b.lockBuffer()
+	b.eachLocked(cb)
+	b.unlockBuffer()
+}
+
+// All returns an iterator over the index and value of everything in the buffer,
+// least recent first. It iterates over a snapshot, so the loop may safely use the buffer.
+func (b *TypedBuffer) All() iter.Seq2[int, interface{}] {
+	b.lockBuffer()
+	values := b.snapshotLocked()
+	b.unlockBuffer()
+
+	return func(yield func(int, interface{}) bool) {
+		for i, value := range values {
+			if !yield(i, value) {
+				return
+			}
 		}
-	} else {
-		index := 0
-		for i = b.at; i < b.capacity; i++ {
-			cb(index, b.values[i])
-			index++
+	}
+}
+
+// Values returns an iterator over the values in the buffer, least recent first.
+// It iterates over a snapshot, so the loop may safely use the buffer.
+func (b *TypedBuffer) Values() iter.Seq[interface{}] {
+	all := b.All()
+	return func(yield func(interface{}) bool) {
+		for _, value := range all {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice returns a newly allocated copy of the values in the buffer, least recent first.
+func (b *TypedBuffer) ToSlice() []interface{} {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	return b.snapshotLocked()
+}
+
+// SnapshotInto copies the values in the buffer, least recent first, into dst, returning how many
+// were copied. Like the builtin copy, this is the smaller of len(dst) and the buffer size, so a
+// caller wanting everything should grow dst when the result equals len(dst) but is less than Size.
+// Nothing is allocated, and the lock is only held for the copy itself, so reusing one dst for
+// repeated snapshots keeps readers from holding up the producer.
+func (b *TypedBuffer) SnapshotInto(dst []interface{}) int {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	n := b.size
+	if n > len(dst) {
+		n = len(dst)
+	}
+	if n == 0 {
+		return 0
+	}
+	// At most two contiguous runs: from the oldest value to the end of storage, then from the start.
+	start := b.physicalIndex(0)
+	copied := copy(dst[:n], b.values[start:])
+	copy(dst[copied:n], b.values)
+	return n
+}
+
+// EachChunk applies a function to each window of size values in the buffer, least recent first,
+// moving the window along by hop values each time. Only full windows are passed, so any
+// trailing values that don't fill a window are skipped. The function receives the index
+// of the first value in the window, and runs on a snapshot so may safely use the buffer.
+func (b *TypedBuffer) EachChunk(size int, hop int, cb func(start int, chunk []interface{})) error {
+	if size < 1 || hop < 1 {
+		return errors.New("EachChunk size and hop must both be at least 1")
+	}
+	b.lockBuffer()
+	values := b.snapshotLocked()
+	b.unlockBuffer()
+
+	for start := 0; start+size <= len(values); start += hop {
+		cb(start, values[start:start+size])
+	}
+	return nil
+}
+
+// Each2 is Each that also passes the index of each value as used by GetFromEnd,
+// so 0 for the most recent value, up to Size() - 1 for the least recent.
+func (b *TypedBuffer) Each2(cb func(chronoIndex int, recencyIndex int, value interface{})) {
+	b.lockBuffer()
+	size := b.size
+	b.eachLocked(func(i int, value interface{}) {
+		cb(i, size-1-i, value)
+	})
+	b.unlockBuffer()
+}
+
+// EachFloat64 is Each for buffers of float64 values. Values of any other type are skipped,
+// so the index passed is still the position of the value within the whole buffer.
+func (b *TypedBuffer) EachFloat64(cb func(int, float64)) {
+	b.Each(func(i int, value interface{}) {
+		if f, ok := value.(float64); ok {
+			cb(i, f)
 		}
-		for i = 0; i < b.at; i++ {
-			cb(index, b.values[i])
-			index++
+	})
+}
+
+// EachInt16 is Each for buffers of int16 PCM values. Values of any other type are skipped,
+// so the index passed is still the position of the value within the whole buffer.
+func (b *TypedBuffer) EachInt16(cb func(int, int16)) {
+	b.Each(func(i int, value interface{}) {
+		if v, ok := value.(int16); ok {
+			cb(i, v)
 		}
+	})
+}
+
+// eachLocked is Each for callers already holding the lock.
+func (b *TypedBuffer) eachLocked(cb func(int, interface{})) {
+	for i := 0; i < b.size; i++ {
+		cb(i, b.values[b.physicalIndex(i)])
 	}
-	b.lock.Unlock()
+}
+
+// physicalIndex converts an index into the buffer, least recent first, to one into b.values.
+// The values always end just before b.at, which need not be at the end of b.values
+// when not full, e.g. after a Clear.
+func (b *TypedBuffer) physicalIndex(index int) int {
+	return (b.at - b.size + index + b.capacity) % b.capacity
+}
+
+// snapshotLocked copies the values in the buffer, least recent first, for callers holding the lock.
+func (b *TypedBuffer) snapshotLocked() []interface{} {
+	result := make([]interface{}, 0, b.size)
+	b.eachLocked(func(i int, value interface{}) {
+		result = append(result, value)
+	})
+	return result
 }
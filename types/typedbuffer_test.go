@@ -0,0 +1,1090 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// countEvents drains everything currently waiting on a channel, without blocking.
+func countEvents(events <-chan struct{}) int {
+	count := 0
+	for {
+		select {
+		case <-events:
+			count++
+		default:
+			return count
+		}
+	}
+}
+
+func TestFullEvents(t *testing.T) {
+	capacity := 5
+	b := NewTypedBuffer(capacity)
+	events := b.FullEvents()
+
+	for i := 0; i < capacity-1; i++ {
+		b.Push(float64(i))
+	}
+	if n := countEvents(events); n != 0 {
+		t.Fatalf("Expected no events before full, got %d", n)
+	}
+
+	for i := capacity - 1; i < 2*capacity; i++ {
+		b.Push(float64(i))
+	}
+	if n := countEvents(events); n != 2 {
+		t.Fatalf("Expected 2 events after %d pushes, got %d", 2*capacity, n)
+	}
+}
+
+func TestFullEventsDropWithoutReceiver(t *testing.T) {
+	b := NewTypedBuffer(1)
+	events := b.FullEvents()
+	for i := 0; i < 2*fullEventsBacklog; i++ {
+		b.Push(float64(i))
+	}
+	if n := countEvents(events); n != fullEventsBacklog {
+		t.Fatalf("Expected %d events kept, got %d", fullEventsBacklog, n)
+	}
+}
+
+func TestLastPushTime(t *testing.T) {
+	b := NewTypedBuffer(3)
+	if !b.LastPushTime().IsZero() || !b.IsStale(time.Hour) {
+		t.Fatalf("Expected a new buffer to have no push time and be stale")
+	}
+
+	before := time.Now()
+	b.Push(1.0)
+	if b.LastPushTime().Before(before) {
+		t.Fatalf("Expected push time after %v, got %v", before, b.LastPushTime())
+	}
+
+	d := 20 * time.Millisecond
+	if b.IsStale(d) {
+		t.Fatalf("Expected buffer to be fresh right after a push")
+	}
+	time.Sleep(2 * d)
+	if !b.IsStale(d) {
+		t.Fatalf("Expected buffer to be stale after %v", 2*d)
+	}
+}
+
+func TestEachFloat64(t *testing.T) {
+	b := NewTypedBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.Push(float64(i))
+	}
+
+	expected := []float64{2, 3, 4}
+	calls := 0
+	b.EachFloat64(func(i int, value float64) {
+		if value != expected[i] {
+			t.Errorf("Index %d: expected %f, got %f", i, expected[i], value)
+		}
+		calls++
+	})
+	if calls != len(expected) {
+		t.Fatalf("Expected %d calls, got %d", len(expected), calls)
+	}
+}
+
+func TestEachFloat64SkipsOtherTypes(t *testing.T) {
+	b := NewTypedBuffer(4)
+	b.Push(1.0)
+	b.Push("not a number")
+	b.Push(int16(3))
+	b.Push(4.0)
+
+	indices := []int{}
+	b.EachFloat64(func(i int, value float64) {
+		indices = append(indices, i)
+	})
+	if len(indices) != 2 || indices[0] != 0 || indices[1] != 3 {
+		t.Fatalf("Expected float values at indices [0 3], got %v", indices)
+	}
+
+	pcm := []int16{}
+	b.EachInt16(func(i int, value int16) {
+		pcm = append(pcm, value)
+	})
+	if len(pcm) != 1 || pcm[0] != 3 {
+		t.Fatalf("Expected int16 values [3], got %v", pcm)
+	}
+}
+
+// assertValues fails the test unless the values are exactly the expected floats.
+func assertValues(t *testing.T, expected []float64, actual []interface{}) {
+	if len(expected) != len(actual) {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestConsumeNInterleaved(t *testing.T) {
+	b := NewTypedBuffer(4)
+	b.Push(1.0)
+	b.Push(2.0)
+	b.Push(3.0)
+
+	values, lost := b.ConsumeN(2)
+	assertValues(t, []float64{1, 2}, values)
+	if lost != 0 {
+		t.Fatalf("Expected nothing lost, got %d", lost)
+	}
+
+	b.Push(4.0)
+	values, _ = b.ConsumeN(10)
+	assertValues(t, []float64{3, 4}, values)
+
+	values, _ = b.ConsumeN(1)
+	assertValues(t, []float64{}, values)
+
+	b.Push(5.0)
+	b.Push(6.0)
+	values, lost = b.ConsumeN(1)
+	assertValues(t, []float64{5}, values)
+	values, lost = b.ConsumeN(1)
+	assertValues(t, []float64{6}, values)
+	if lost != 0 {
+		t.Fatalf("Expected nothing lost, got %d", lost)
+	}
+}
+
+func TestConsumeNOverrun(t *testing.T) {
+	b := NewTypedBuffer(3)
+	for i := 1; i <= 7; i++ {
+		b.Push(float64(i))
+	}
+
+	values, lost := b.ConsumeN(2)
+	assertValues(t, []float64{5, 6}, values)
+	if lost != 4 {
+		t.Fatalf("Expected 4 values lost, got %d", lost)
+	}
+
+	// Overwriting already consumed values loses nothing.
+	b.Push(8.0)
+	b.Push(9.0)
+	values, lost = b.ConsumeN(5)
+	assertValues(t, []float64{7, 8, 9}, values)
+	if lost != 0 {
+		t.Fatalf("Expected nothing lost, got %d", lost)
+	}
+}
+
+func TestOverrunEvents(t *testing.T) {
+	b := NewTypedBuffer(3)
+	events := b.OverrunEvents()
+	for i := 1; i <= 3; i++ {
+		b.Push(float64(i))
+	}
+	select {
+	case count := <-events:
+		t.Fatalf("Expected no overrun filling the buffer, got %d", count)
+	default:
+	}
+
+	b.Push(4.0)
+	if count := <-events; count != 1 {
+		t.Fatalf("Expected 1 value lost, got %d", count)
+	}
+
+	// Unreceived events are combined.
+	b.Push(5.0)
+	b.Push(6.0)
+	if count := <-events; count != 2 {
+		t.Fatalf("Expected 2 values lost, got %d", count)
+	}
+
+	// Overwriting consumed values is not an overrun.
+	b.ConsumeN(3)
+	b.Push(7.0)
+	select {
+	case count := <-events:
+		t.Fatalf("Expected no overrun after consuming, got %d", count)
+	default:
+	}
+}
+
+func TestOverrunEventsSlowConsumer(t *testing.T) {
+	b := NewTypedBuffer(8)
+	events := b.OverrunEvents()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10000; i++ {
+			b.Push(float64(i))
+		}
+		close(done)
+	}()
+
+	var reported, lost uint64
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		case count := <-events:
+			reported += uint64(count)
+		}
+		_, newlyLost := b.ConsumeN(1)
+		lost += newlyLost
+	}
+	_, newlyLost := b.ConsumeN(1)
+	lost += newlyLost
+	select {
+	case count := <-events:
+		reported += uint64(count)
+	default:
+	}
+
+	if lost == 0 {
+		t.Fatalf("Expected the producer to overrun the consumer")
+	}
+	if reported != lost {
+		t.Errorf("Expected overrun events to total the %d values lost, got %d", lost, reported)
+	}
+}
+
+func TestEachChunk(t *testing.T) {
+	b := NewTypedBuffer(10)
+	for i := 0; i < 13; i++ {
+		b.Push(float64(i))
+	}
+
+	tests := []struct {
+		size, hop int
+		starts    []int
+	}{
+		{10, 1, []int{0}},
+		{4, 4, []int{0, 4}},
+		{4, 2, []int{0, 2, 4, 6}},
+		{3, 5, []int{0, 5}},
+		{1, 3, []int{0, 3, 6, 9}},
+		{11, 1, []int{}},
+	}
+	for _, test := range tests {
+		starts := []int{}
+		err := b.EachChunk(test.size, test.hop, func(start int, chunk []interface{}) {
+			if len(chunk) != test.size {
+				t.Errorf("size %d hop %d: expected chunk of %d, got %d", test.size, test.hop, test.size, len(chunk))
+			}
+			// Values oldest first are 3, 4, ..., 12
+			if chunk[0] != float64(start+3) {
+				t.Errorf("size %d hop %d: chunk at %d starts with %v", test.size, test.hop, start, chunk[0])
+			}
+			starts = append(starts, start)
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(starts) != len(test.starts) {
+			t.Errorf("size %d hop %d: expected chunks at %v, got %v", test.size, test.hop, test.starts, starts)
+			continue
+		}
+		for i := range starts {
+			if starts[i] != test.starts[i] {
+				t.Errorf("size %d hop %d: expected chunks at %v, got %v", test.size, test.hop, test.starts, starts)
+				break
+			}
+		}
+	}
+}
+
+func TestEachChunkInvalid(t *testing.T) {
+	b := NewTypedBuffer(4)
+	cb := func(start int, chunk []interface{}) {}
+	if b.EachChunk(0, 1, cb) == nil || b.EachChunk(1, 0, cb) == nil {
+		t.Errorf("Expected errors for size or hop below 1")
+	}
+}
+
+func TestZeroCapacityPassthrough(t *testing.T) {
+	b := NewTypedBuffer(0)
+	for i := 0; i < 3; i++ {
+		if evicted := b.Push(float64(i)); evicted != float64(i) {
+			t.Fatalf("Expected %d evicted immediately, got %v", i, evicted)
+		}
+	}
+	if !b.IsFull() || b.Size() != 0 {
+		t.Fatalf("Expected a full buffer of size 0, got size %d", b.Size())
+	}
+	if value := b.GetFromEnd(0); value != 0.0 {
+		t.Fatalf("Expected default value, got %v", value)
+	}
+	b.Each(func(i int, value interface{}) {
+		t.Fatalf("Expected no values, got %v at %d", value, i)
+	})
+}
+
+func TestAllInOrder(t *testing.T) {
+	b := NewTypedBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.Push(float64(i))
+	}
+
+	expected := []float64{2, 3, 4}
+	count := 0
+	for i, v := range b.All() {
+		if i != count || v != expected[i] {
+			t.Fatalf("Expected %v at %d, got %v at %d", expected[count], count, v, i)
+		}
+		// Pushing inside the loop doesn't affect what is being iterated.
+		b.Push(100.0 + float64(i))
+		count++
+	}
+	if count != len(expected) {
+		t.Fatalf("Expected %d values, got %d", len(expected), count)
+	}
+
+	values := []interface{}{}
+	for v := range b.Values() {
+		values = append(values, v)
+	}
+	assertValues(t, []float64{100, 101, 102}, values)
+}
+
+func TestAllBreak(t *testing.T) {
+	b := newFloatBuffer([]float64{1, 2, 3})
+	count := 0
+	for range b.All() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Fatalf("Expected iteration to stop after 1 value, got %d", count)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	b := NewTypedBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.Push(float64(i))
+	}
+	if b.Pushed() != 5 || b.Evicted() != 2 {
+		t.Fatalf("Expected 5 pushed and 2 evicted, got %d and %d", b.Pushed(), b.Evicted())
+	}
+
+	// Counters wrap rather than overflow into anything odd.
+	b.pushed = math.MaxUint64
+	b.Push(5.0)
+	if b.Pushed() != 0 {
+		t.Fatalf("Expected pushed count to wrap to 0, got %d", b.Pushed())
+	}
+
+	b.ResetCounters()
+	if b.Pushed() != 0 || b.Evicted() != 0 {
+		t.Fatalf("Expected counters reset, got %d pushed and %d evicted", b.Pushed(), b.Evicted())
+	}
+	if _, lost := b.ConsumeN(0); lost != 0 {
+		t.Fatalf("Expected lost count reset, got %d", lost)
+	}
+	assertValues(t, []float64{3, 4, 5}, b.Freeze().values)
+}
+
+func TestGoPushMerge(t *testing.T) {
+	lengths := []int{5, 50, 20}
+	total := 0
+	channels := make([]chan interface{}, len(lengths))
+	sources := make([]<-chan interface{}, len(lengths))
+	for i, length := range lengths {
+		channels[i] = make(chan interface{})
+		sources[i] = channels[i]
+		total += length
+	}
+
+	b := NewTypedBuffer(total)
+	b.GoPushMerge(sources, 1)
+
+	// Close the channels at different times, checking the buffer only finishes after the last.
+	for i, length := range lengths {
+		for j := 0; j < length; j++ {
+			channels[i] <- float64(1000*i + j)
+		}
+		if i < len(lengths)-1 {
+			close(channels[i])
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if b.IsFinished() {
+		t.Fatalf("Expected buffer to not be finished while a source is open")
+	}
+	close(channels[len(lengths)-1])
+
+	deadline := time.Now().Add(time.Second)
+	for !b.IsFinished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected buffer to finish after all sources closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if b.Size() != total {
+		t.Fatalf("Expected %d values, got %d", total, b.Size())
+	}
+	seen := map[float64]bool{}
+	b.EachFloat64(func(i int, v float64) {
+		seen[v] = true
+	})
+	for source, length := range lengths {
+		for j := 0; j < length; j++ {
+			if !seen[float64(1000*source+j)] {
+				t.Errorf("Missing value %d from source %d", j, source)
+			}
+		}
+	}
+}
+
+func TestUnsafeMatchesLocked(t *testing.T) {
+	locked := NewTypedBuffer(7)
+	unsafe := NewTypedBufferUnsafe(7)
+	for i := 0; i < 25; i++ {
+		if a, b := locked.Push(float64(i)), unsafe.Push(float64(i)); a != b {
+			t.Fatalf("Push %d: locked evicted %v, unsafe evicted %v", i, a, b)
+		}
+		for j := 0; j < 7; j++ {
+			if a, b := locked.GetFromEnd(j), unsafe.GetFromEnd(j); a != b {
+				t.Fatalf("GetFromEnd(%d) after %d pushes: locked %v, unsafe %v", j, i, a, b)
+			}
+		}
+		if locked.Freeze().Hash() != unsafe.Freeze().Hash() {
+			t.Fatalf("Contents differ after %d pushes", i)
+		}
+	}
+}
+
+func benchmarkPush(bench *testing.B, b *TypedBuffer) {
+	for i := 0; i < bench.N; i++ {
+		b.Push(1.0)
+		b.GetFromEnd(0)
+	}
+}
+
+func BenchmarkPushLocked(bench *testing.B) {
+	benchmarkPush(bench, NewTypedBuffer(1024))
+}
+
+func BenchmarkPushUnsafe(bench *testing.B) {
+	benchmarkPush(bench, NewTypedBufferUnsafe(1024))
+}
+
+func TestSnapshotIntoMatchesToSlice(t *testing.T) {
+	b := NewTypedBuffer(5)
+	dst := make([]interface{}, 5)
+	for i := 0; i < 13; i++ {
+		if i == 7 {
+			b.Clear()
+		}
+		b.Push(float64(i))
+
+		expected := b.ToSlice()
+		n := b.SnapshotInto(dst)
+		if !reflect.DeepEqual(expected, dst[:n]) {
+			t.Fatalf("After %d pushes: expected %v, got %v", i+1, expected, dst[:n])
+		}
+	}
+
+	// Short destinations get the oldest values that fit.
+	short := make([]interface{}, 2)
+	if n := b.SnapshotInto(short); n != 2 || short[0] != 8.0 || short[1] != 9.0 {
+		t.Errorf("Expected [8 9], got %v", short[:n])
+	}
+	if n := NewTypedBuffer(0).SnapshotInto(dst); n != 0 {
+		t.Errorf("Expected nothing copied from a zero capacity buffer, got %d", n)
+	}
+}
+
+func BenchmarkSnapshotInto(bench *testing.B) {
+	b := NewTypedBuffer(1024)
+	for i := 0; i < 1500; i++ {
+		b.Push(float64(i))
+	}
+	dst := make([]interface{}, 1024)
+	bench.ReportAllocs()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		b.SnapshotInto(dst)
+	}
+}
+
+func BenchmarkToSlice(bench *testing.B) {
+	b := NewTypedBuffer(1024)
+	for i := 0; i < 1500; i++ {
+		b.Push(float64(i))
+	}
+	bench.ReportAllocs()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		b.ToSlice()
+	}
+}
+
+func TestEach2Indices(t *testing.T) {
+	for _, pushes := range []int{3, 6, 11} {
+		b := NewTypedBuffer(6)
+		for i := 0; i < pushes; i++ {
+			b.Push(float64(i))
+		}
+		calls := 0
+		b.Each2(func(chrono int, recency int, value interface{}) {
+			if chrono+recency != b.size-1 {
+				t.Errorf("%d pushes: indices %d and %d don't sum to %d", pushes, chrono, recency, b.size-1)
+			}
+			if value != float64(pushes-1-recency) {
+				t.Errorf("%d pushes: expected %d at recency %d, got %v", pushes, pushes-1-recency, recency, value)
+			}
+			calls++
+		})
+		if calls != b.Size() {
+			t.Errorf("%d pushes: expected %d calls, got %d", pushes, b.Size(), calls)
+		}
+	}
+}
+
+func TestNegativeCapacityPanics(t *testing.T) {
+	defer func() {
+		expected := "NewTypedBuffer capacity must not be negative, got -3"
+		if r := recover(); r != expected {
+			t.Fatalf("Expected panic %q, got %v", expected, r)
+		}
+	}()
+	NewTypedBuffer(-3)
+}
+
+func TestConcat(t *testing.T) {
+	a := NewTypedBuffer(3)
+	for i := 0; i < 5; i++ {
+		a.Push(float64(i))
+	}
+	b := newFloatBuffer([]float64{10, 11})
+
+	c := Concat(a, b)
+	if c.Size() != 5 || !c.IsFull() {
+		t.Fatalf("Expected a full buffer of 5, got size %d", c.Size())
+	}
+	assertValues(t, []float64{2, 3, 4, 10, 11}, c.Freeze().values)
+	assertValues(t, []float64{2, 3, 4}, a.Freeze().values)
+
+	empty := NewTypedBuffer(4)
+	assertValues(t, []float64{10, 11}, Concat(empty, b).Freeze().values)
+	assertValues(t, []float64{10, 11}, Concat(b, empty).Freeze().values)
+	assertValues(t, []float64{10, 11, 10, 11}, Concat(b, b).Freeze().values)
+}
+
+func TestStreamChunks(t *testing.T) {
+	b := NewTypedBuffer(5)
+	chunks := [][]float64{}
+	if err := b.StreamChunks(3, func(chunk []float64) {
+		chunks = append(chunks, chunk)
+	}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for i := 0; i < 11; i++ {
+		b.Push(float64(i))
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %v", chunks)
+	}
+	for c, chunk := range chunks {
+		assertClose(t, []float64{float64(3 * c), float64(3*c + 1), float64(3*c + 2)}, chunk, 0)
+	}
+
+	// Stopping streaming stops the chunks.
+	b.StreamChunks(0, nil)
+	for i := 0; i < 6; i++ {
+		b.Push(0.0)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Expected no more chunks after stopping, got %d", len(chunks))
+	}
+}
+
+func TestStreamChunksAfterShrinking(t *testing.T) {
+	b := NewTypedBuffer(5)
+	chunks := [][]float64{}
+	b.StreamChunks(3, func(chunk []float64) {
+		chunks = append(chunks, chunk)
+	})
+
+	// Values cleared before they complete a chunk are never streamed.
+	b.Push(0.0)
+	b.Push(1.0)
+	b.Clear()
+	for i := 10; i <= 12; i++ {
+		b.Push(float64(i))
+	}
+
+	// Nor are values popped before they complete one.
+	b.Push(13.0)
+	b.Push(14.0)
+	for i := 0; i < 4; i++ {
+		b.PopBlocking(context.Background())
+	}
+	b.Push(15.0)
+	b.Push(16.0)
+
+	expected := [][]float64{{10, 11, 12}, {14, 15, 16}}
+	if !reflect.DeepEqual(expected, chunks) {
+		t.Errorf("Expected chunks %v, got %v", expected, chunks)
+	}
+}
+
+func TestStreamChunksInvalidSize(t *testing.T) {
+	b := NewTypedBuffer(4)
+	sink := func(chunk []float64) {}
+	if err := b.StreamChunks(0, sink); err == nil {
+		t.Errorf("Expected error for a chunk size of 0")
+	}
+	if err := b.StreamChunks(5, sink); err == nil {
+		t.Errorf("Expected error for a chunk size larger than the capacity")
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	b := NewTypedBuffer(6)
+	for i := 0; i < 10; i++ {
+		b.Push(float64(i))
+	}
+
+	values, err := b.GetRange(1, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertValues(t, []float64{5, 6, 7}, values)
+
+	values, _ = b.GetRange(0, 6)
+	assertValues(t, []float64{4, 5, 6, 7, 8, 9}, values)
+	values, _ = b.GetRange(3, 3)
+	assertValues(t, []float64{}, values)
+
+	for _, r := range [][2]int{{-1, 2}, {2, 7}, {4, 3}} {
+		if _, err := b.GetRange(r[0], r[1]); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Range %v: expected ErrIndexOutOfRange, got %v", r, err)
+		}
+	}
+}
+
+func TestIsStaleWithFakeClock(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewTypedBuffer(3)
+	b.SetClock(func() time.Time {
+		return now
+	})
+
+	b.Push(1.0)
+	if !b.LastPushTime().Equal(now) {
+		t.Fatalf("Expected push time %v, got %v", now, b.LastPushTime())
+	}
+	now = now.Add(time.Second)
+	if b.IsStale(time.Second) {
+		t.Fatalf("Expected buffer to not yet be stale")
+	}
+	now = now.Add(time.Nanosecond)
+	if !b.IsStale(time.Second) {
+		t.Fatalf("Expected buffer to be stale")
+	}
+	b.Push(2.0)
+	if b.IsStale(time.Second) {
+		t.Fatalf("Expected buffer to be fresh after a push")
+	}
+}
+
+func TestPushUnique(t *testing.T) {
+	b := NewTypedBuffer(10)
+	pushed := 0
+	for _, v := range []float64{1, 1, 1, 2, 2, 1, 3, 3, 3, 3} {
+		if b.PushUnique(v) {
+			pushed++
+		}
+	}
+	if pushed != 4 {
+		t.Fatalf("Expected 4 pushes, got %d", pushed)
+	}
+	assertValues(t, []float64{1, 2, 1, 3}, b.Freeze().values)
+
+	// Uncomparable values are always pushed.
+	if !b.PushUnique([]int{1}) || !b.PushUnique([]int{1}) {
+		t.Fatalf("Expected uncomparable values to always be pushed")
+	}
+	if b.Size() != 6 {
+		t.Fatalf("Expected 6 values, got %d", b.Size())
+	}
+}
+
+func TestOccupancyHistogram(t *testing.T) {
+	b := NewTypedBuffer(20)
+	if b.OccupancyHistogram() != nil {
+		t.Fatalf("Expected no histogram before tracking is enabled")
+	}
+	b.EnableOccupancyTracking()
+
+	// Fill up, then keep pushing while full, then clear and half fill.
+	for i := 0; i < 30; i++ {
+		b.Push(float64(i))
+	}
+	b.Clear()
+	for i := 0; i < 10; i++ {
+		b.Push(float64(i))
+	}
+
+	expected := []int{4, 4, 4, 4, 4, 2, 2, 2, 2, 12}
+	histogram := b.OccupancyHistogram()
+	for i := range expected {
+		if histogram[i] != expected[i] {
+			t.Fatalf("Expected histogram %v, got %v", expected, histogram)
+		}
+	}
+}
+
+// assertFromEnd fails the test unless GetFromEnd returns the expected values, most recent first,
+// and the default for the rest of the capacity.
+func assertFromEnd(t *testing.T, b *TypedBuffer, newestFirst []float64) {
+	for i := 0; i < b.capacity; i++ {
+		expected := 0.0
+		if i < len(newestFirst) {
+			expected = newestFirst[i]
+		}
+		if actual := b.GetFromEnd(i); actual != expected {
+			t.Fatalf("GetFromEnd(%d): expected %v, got %v", i, expected, actual)
+		}
+	}
+}
+
+func TestGetFromEndOrder(t *testing.T) {
+	b := NewTypedBuffer(4)
+	b.Push(1.0)
+	b.Push(2.0)
+	assertFromEnd(t, b, []float64{2, 1})
+	for i := 3; i <= 6; i++ {
+		b.Push(float64(i))
+	}
+	assertFromEnd(t, b, []float64{6, 5, 4, 3})
+}
+
+func TestGetFromEndAfterClear(t *testing.T) {
+	b := NewTypedBuffer(5)
+	for i := 0; i < 3; i++ {
+		b.Push(float64(i))
+	}
+	// Clear leaves the old values behind, and the write position partway through.
+	b.Clear()
+	b.Push(10.0)
+	b.Push(11.0)
+	assertFromEnd(t, b, []float64{11, 10})
+	assertValues(t, []float64{10, 11}, b.Freeze().values)
+
+	// Wrap around the end of the backing array while still not full.
+	b.Push(12.0)
+	b.Push(13.0)
+	assertFromEnd(t, b, []float64{13, 12, 11, 10})
+	assertValues(t, []float64{10, 11, 12, 13}, b.Freeze().values)
+	_, _, at, _, _ := b.DumpState()
+	if at != 2 {
+		t.Fatalf("Expected writes to have wrapped to 2, got %d", at)
+	}
+
+	b.Push(14.0)
+	b.Push(15.0)
+	assertFromEnd(t, b, []float64{15, 14, 13, 12, 11})
+}
+
+func TestGetFromEndNegative(t *testing.T) {
+	b := NewTypedBuffer(4)
+	b.Push(1.0)
+	b.Push(2.0)
+	b.Push(3.0)
+	// Partially filled: negative indices only reach the values pushed.
+	for index, expected := range map[int]float64{0: 3, 2: 1, 3: 0, -1: 1, -2: 2, -3: 3} {
+		if actual := b.GetFromEnd(index); actual != expected {
+			t.Errorf("Partial GetFromEnd(%d): expected %v, got %v", index, expected, actual)
+		}
+	}
+	if _, err := b.TryGetFromEnd(-4); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange past the oldest value, got %v", err)
+	}
+
+	// Full and wrapped.
+	for i := 4; i <= 6; i++ {
+		b.Push(float64(i))
+	}
+	for index, expected := range map[int]float64{0: 6, 3: 3, -1: 3, -4: 6} {
+		if actual := b.GetFromEnd(index); actual != expected {
+			t.Errorf("Full GetFromEnd(%d): expected %v, got %v", index, expected, actual)
+		}
+	}
+	for _, index := range []int{4, -5} {
+		if _, err := b.TryGetFromEnd(index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Index %d: expected ErrIndexOutOfRange, got %v", index, err)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected GetFromEnd to panic past the oldest value")
+		}
+	}()
+	b.GetFromEnd(-5)
+}
+
+func TestPopBlockingWaitsForPush(t *testing.T) {
+	b := NewTypedBuffer(3)
+	popped := make(chan interface{})
+	go func() {
+		value, err := b.PopBlocking(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		popped <- value
+	}()
+
+	select {
+	case value := <-popped:
+		t.Fatalf("Expected pop to block on an empty buffer, got %v", value)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	b.Push(1.0)
+	b.Push(2.0)
+	select {
+	case value := <-popped:
+		if value != 1.0 && value != 2.0 {
+			t.Fatalf("Expected a pushed value, got %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected pop to unblock after a push")
+	}
+
+	// Popping takes the oldest first.
+	b.Push(3.0)
+	b.Push(4.0)
+	b.Push(5.0)
+	for _, expected := range []float64{3, 4, 5} {
+		if value, _ := b.PopBlocking(context.Background()); value != expected {
+			t.Fatalf("Expected %v popped, got %v", expected, value)
+		}
+	}
+	if b.Size() != 0 {
+		t.Fatalf("Expected an empty buffer, got size %d", b.Size())
+	}
+}
+
+func TestPopBlockingCancelled(t *testing.T) {
+	b := NewTypedBuffer(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := b.PopBlocking(ctx)
+		errs <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected pop to return promptly once cancelled")
+	}
+}
+
+func TestPushBlockingWaitsForPop(t *testing.T) {
+	b := NewTypedBuffer(2)
+	ctx := context.Background()
+	b.PushBlocking(ctx, 1.0)
+	b.PushBlocking(ctx, 2.0)
+
+	pushed := make(chan error)
+	go func() {
+		pushed <- b.PushBlocking(ctx, 3.0)
+	}()
+	select {
+	case <-pushed:
+		t.Fatalf("Expected push to block on a full buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if value, _ := b.PopBlocking(ctx); value != 1.0 {
+		t.Fatalf("Expected 1 popped, got %v", value)
+	}
+	if err := <-pushed; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertValues(t, []float64{2, 3}, b.Freeze().values)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := b.PushBlocking(timeout, 4.0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestElementKind(t *testing.T) {
+	if kind := NewTypedBuffer(2).ElementKind(); kind != reflect.Invalid {
+		t.Errorf("Expected Invalid for an untyped buffer, got %s", kind)
+	}
+	if kind := NewComplexBuffer(2).ElementKind(); kind != reflect.Complex128 {
+		t.Errorf("Expected Complex128 for a complex buffer, got %s", kind)
+	}
+
+	b := NewStrictTypedBuffer(2, reflect.Float64)
+	if kind := b.ElementKind(); kind != reflect.Float64 {
+		t.Errorf("Expected Float64, got %s", kind)
+	}
+	b.Push(1.0)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected a panic pushing the wrong kind")
+		}
+		// The lock must not be left held.
+		b.Push(2.0)
+		assertValues(t, []float64{1, 2}, b.Freeze().values)
+	}()
+	b.Push(int16(1))
+}
+
+func TestResizeKeepsMostRecent(t *testing.T) {
+	b := NewTypedBuffer(4)
+	for i := 1; i <= 6; i++ {
+		b.PushWeighted(float64(i), float64(i))
+	}
+
+	if err := b.Resize(2); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertValues(t, []float64{5, 6}, b.Freeze().values)
+	if actual := b.WeightedSum(); actual != 25+36 {
+		t.Errorf("Expected weights to shrink with the values, got %f", actual)
+	}
+
+	if err := b.Resize(5); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertValues(t, []float64{5, 6}, b.Freeze().values)
+	b.Push(7.0)
+	b.Push(8.0)
+	assertValues(t, []float64{5, 6, 7, 8}, b.Freeze().values)
+	assertFromEnd(t, b, []float64{8, 7, 6, 5})
+	if actual := b.WeightedSum(); actual != 25+36+7+8 {
+		t.Errorf("Expected new values weighted 1 after growing, got %f", actual)
+	}
+
+	if err := b.Resize(-1); !errors.Is(err, ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+}
+
+func TestOnResize(t *testing.T) {
+	b := NewTypedBuffer(3)
+	var calls [][2]int
+	b.SetOnResize(func(oldCapacity, newCapacity int) {
+		// The lock must be released by now.
+		b.Push(0.0)
+		calls = append(calls, [2]int{oldCapacity, newCapacity})
+	})
+
+	b.Resize(8)
+	b.Resize(8)
+	b.Resize(2)
+	expected := [][2]int{{3, 8}, {8, 2}}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("Expected resizes %v, got %v", expected, calls)
+	}
+
+	b.SetOnResize(nil)
+	b.Resize(4)
+	if len(calls) != 2 {
+		t.Errorf("Expected no callback once cleared, got %v", calls)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	b := NewTypedBuffer(4)
+	for i := 1; i <= 6; i++ {
+		b.Push(float64(i))
+	}
+	b.ConsumeN(4)
+
+	old := b.Swap([]interface{}{10.0, 11.0})
+	assertValues(t, []float64{3, 4, 5, 6}, old)
+	assertValues(t, []float64{10, 11}, b.ToSlice())
+	assertFromEnd(t, b, []float64{11, 10})
+	if values, _ := b.ConsumeN(10); len(values) != 2 {
+		t.Errorf("Expected the swapped in values to be unread, got %v", values)
+	}
+
+	// Pushing carries on from the swapped in values.
+	b.Push(12.0)
+	assertValues(t, []float64{10, 11, 12}, b.ToSlice())
+
+	// Too many values keeps the most recent.
+	old = b.Swap([]interface{}{1.0, 2.0, 3.0, 4.0, 5.0})
+	assertValues(t, []float64{10, 11, 12}, old)
+	assertValues(t, []float64{2, 3, 4, 5}, b.ToSlice())
+	b.Push(6.0)
+	assertValues(t, []float64{3, 4, 5, 6}, b.ToSlice())
+
+	old = b.Swap(nil)
+	assertValues(t, []float64{3, 4, 5, 6}, old)
+	if b.Size() != 0 {
+		t.Errorf("Expected swapping in nothing to empty the buffer, got size %d", b.Size())
+	}
+}
+
+func TestSwapClearsOldSlots(t *testing.T) {
+	b := NewTypedBuffer(4)
+	for i := 1; i <= 4; i++ {
+		b.Push(float64(i))
+	}
+	b.Swap([]interface{}{10.0})
+	for i := 1; i < 4; i++ {
+		if b.values[i] != nil {
+			t.Errorf("Expected slot %d to be cleared, got %v", i, b.values[i])
+		}
+	}
+}
+
+func TestSwapRestartsChunks(t *testing.T) {
+	b := NewTypedBuffer(5)
+	chunks := [][]float64{}
+	b.StreamChunks(3, func(chunk []float64) {
+		chunks = append(chunks, chunk)
+	})
+	b.Push(1.0)
+	b.Push(2.0)
+	b.Swap([]interface{}{10.0})
+	for i := 11; i <= 13; i++ {
+		b.Push(float64(i))
+	}
+	expected := [][]float64{{11, 12, 13}}
+	if !reflect.DeepEqual(expected, chunks) {
+		t.Errorf("Expected chunks %v, got %v", expected, chunks)
+	}
+}
+
+func TestSwapStrict(t *testing.T) {
+	b := NewStrictTypedBuffer(3, reflect.Slice)
+	b.Push([]float64{1})
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected a panic swapping in the wrong kind")
+		}
+		// Nothing is changed, and the lock is not left held.
+		if b.Size() != 1 {
+			t.Errorf("Expected the contents to be untouched")
+		}
+	}()
+	b.Swap([]interface{}{[]float64{2}, "x"})
+}
@@ -0,0 +1,115 @@
+// Reading and writing typed buffers as .wav files.
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+)
+
+const (
+	// Layout of the 16-bit PCM mono .wav files supported.
+	wavHeaderSize    = 44
+	wavFormatPCM     = 1
+	wavBitsPerSample = 16
+	wavBytesPerFrame = wavBitsPerSample / 8
+)
+
+// WriteWAV writes the float64 values in the buffer, least recent first, to a 16-bit PCM mono .wav file.
+// Values are clamped to [-1, 1] first.
+func (b *TypedBuffer) WriteWAV(path string, sampleRate int) error {
+	values := b.floatValues()
+	dataSize := len(values) * wavBytesPerFrame
+
+	out := bytes.NewBuffer(make([]byte, 0, wavHeaderSize+dataSize))
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(wavHeaderSize-8+dataSize))
+	out.WriteString("WAVE")
+
+	out.WriteString("fmt ")
+	binary.Write(out, binary.LittleEndian, uint32(16)) /* fmt chunk size */
+	binary.Write(out, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(out, binary.LittleEndian, uint16(1)) /* channels */
+	binary.Write(out, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(out, binary.LittleEndian, uint32(sampleRate*wavBytesPerFrame))
+	binary.Write(out, binary.LittleEndian, uint16(wavBytesPerFrame))
+	binary.Write(out, binary.LittleEndian, uint16(wavBitsPerSample))
+
+	out.WriteString("data")
+	binary.Write(out, binary.LittleEndian, uint32(dataSize))
+	for _, v := range values {
+		v = math.Max(-1.0, math.Min(1.0, v))
+		binary.Write(out, binary.LittleEndian, int16(v*math.MaxInt16))
+	}
+
+	return ioutil.WriteFile(path, out.Bytes(), 0644)
+}
+
+// ReadWAV loads a 16-bit PCM mono .wav file into a buffer exactly large enough to hold
+// all its samples, converted to float64 values in [-1, 1]. The sample rate is also returned.
+func ReadWAV(path string) (*TypedBuffer, int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, errors.New("Not a RIFF/WAVE file: " + path)
+	}
+
+	sampleRate := 0
+	haveFormat := false
+	for at := 12; at+8 <= len(data); {
+		id := string(data[at : at+4])
+		size := int(binary.LittleEndian.Uint32(data[at+4 : at+8]))
+		chunk := data[at+8:]
+		if size > len(chunk) {
+			if id != "data" {
+				return nil, 0, fmt.Errorf("Truncated %q chunk in %s", id, path)
+			}
+			// Some writers don't fix up the data size, so read as many samples as exist.
+			size = len(chunk)
+		}
+		chunk = chunk[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("Malformed fmt chunk in " + path)
+			}
+			format := binary.LittleEndian.Uint16(chunk[0:2])
+			channels := binary.LittleEndian.Uint16(chunk[2:4])
+			bits := binary.LittleEndian.Uint16(chunk[14:16])
+			if format != wavFormatPCM {
+				return nil, 0, fmt.Errorf("Unsupported wav format %d, only PCM is supported", format)
+			}
+			if channels != 1 {
+				return nil, 0, fmt.Errorf("Unsupported wav with %d channels, only mono is supported", channels)
+			}
+			if bits != wavBitsPerSample {
+				return nil, 0, fmt.Errorf("Unsupported %d-bit wav, only 16-bit is supported", bits)
+			}
+			sampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return nil, 0, errors.New("Wav data chunk found before fmt chunk in " + path)
+			}
+			count := size / wavBytesPerFrame
+			b := NewTypedBuffer(count)
+			for i := 0; i < count; i++ {
+				sample := int16(binary.LittleEndian.Uint16(chunk[i*wavBytesPerFrame:]))
+				// Scale to match WriteWAV, clamping -32768 which would land just below -1.
+				b.Push(math.Max(-1.0, float64(sample)/math.MaxInt16))
+			}
+			return b, sampleRate, nil
+		}
+
+		// Chunks are padded to an even number of bytes.
+		at += 8 + size + size%2
+	}
+	return nil, 0, errors.New("No wav data chunk found in " + path)
+}
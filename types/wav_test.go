@@ -0,0 +1,145 @@
+package types
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteWAVHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav_")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buffer.wav")
+
+	values := []float64{0, 0.5, -0.5, 1, -1, 2, -2}
+	if err := newFloatBuffer(values).WriteWAV(path, 8000); err != nil {
+		t.Fatalf("Error writing wav: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading wav: %s", err)
+	}
+	dataSize := len(values) * 2
+	if len(data) != 44+dataSize {
+		t.Fatalf("Expected %d bytes, got %d", 44+dataSize, len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" ||
+		string(data[12:16]) != "fmt " || string(data[36:40]) != "data" {
+		t.Fatalf("Malformed chunk identifiers in header: %q", data[:44])
+	}
+	if riff := binary.LittleEndian.Uint32(data[4:8]); riff != uint32(36+dataSize) {
+		t.Errorf("Expected RIFF size %d, got %d", 36+dataSize, riff)
+	}
+	if channels := binary.LittleEndian.Uint16(data[22:24]); channels != 1 {
+		t.Errorf("Expected 1 channel, got %d", channels)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != 8000 {
+		t.Errorf("Expected sample rate 8000, got %d", rate)
+	}
+	if bits := binary.LittleEndian.Uint16(data[34:36]); bits != 16 {
+		t.Errorf("Expected 16 bits per sample, got %d", bits)
+	}
+	if size := binary.LittleEndian.Uint32(data[40:44]); size != uint32(dataSize) {
+		t.Errorf("Expected data size %d, got %d", dataSize, size)
+	}
+
+	// Out of range values are clamped.
+	last := int16(binary.LittleEndian.Uint16(data[44+2*6:]))
+	if last != -math.MaxInt16 {
+		t.Errorf("Expected clamped sample %d, got %d", -math.MaxInt16, last)
+	}
+}
+
+func TestReadWAVFixture(t *testing.T) {
+	path := filepath.Join("..", "test", "sampler.wav")
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading fixture: %s", err)
+	}
+
+	b, sampleRate, err := ReadWAV(path)
+	if err != nil {
+		t.Fatalf("Error loading wav: %s", err)
+	}
+	if sampleRate != 44100 {
+		t.Errorf("Expected sample rate 44100, got %d", sampleRate)
+	}
+	count := (len(raw) - 44) / 2
+	if b.Size() != count || !b.IsFull() {
+		t.Fatalf("Expected a full buffer of %d samples, got %d", count, b.Size())
+	}
+
+	b.EachFloat64(func(i int, value float64) {
+		expected := math.Max(-1, float64(int16(binary.LittleEndian.Uint16(raw[44+2*i:])))/math.MaxInt16)
+		if value != expected {
+			t.Fatalf("Sample %d: expected %f, got %f", i, expected, value)
+		}
+	})
+}
+
+func TestReadWAVRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav_")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buffer.wav")
+
+	values := []float64{0, 0.25, -0.25, 1, -1}
+	if err := newFloatBuffer(values).WriteWAV(path, 22050); err != nil {
+		t.Fatalf("Error writing wav: %s", err)
+	}
+	b, sampleRate, err := ReadWAV(path)
+	if err != nil {
+		t.Fatalf("Error loading wav: %s", err)
+	}
+	if sampleRate != 22050 {
+		t.Errorf("Expected sample rate 22050, got %d", sampleRate)
+	}
+	assertClose(t, values, b.floatValues(), 1.0/math.MaxInt16)
+}
+
+func TestReadWAVMostNegativeSample(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wav_")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buffer.wav")
+
+	if err := newFloatBuffer([]float64{0, 0}).WriteWAV(path, 8000); err != nil {
+		t.Fatalf("Error writing wav: %s", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading wav: %s", err)
+	}
+	binary.LittleEndian.PutUint16(data[44:], 0x8000)
+	binary.LittleEndian.PutUint16(data[46:], math.MaxInt16)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Error writing wav: %s", err)
+	}
+
+	b, _, err := ReadWAV(path)
+	if err != nil {
+		t.Fatalf("Error loading wav: %s", err)
+	}
+	assertClose(t, []float64{-1, 1}, b.floatValues(), 0)
+}
+
+func TestReadWAVUnsupported(t *testing.T) {
+	// The piano sample is in stereo.
+	if _, _, err := ReadWAV(filepath.Join("..", "piano.wav")); err == nil {
+		t.Errorf("Expected error loading a stereo wav")
+	}
+	if _, _, err := ReadWAV(filepath.Join("..", "README.md")); err == nil {
+		t.Errorf("Expected error loading a non-wav file")
+	}
+}
@@ -0,0 +1,58 @@
+// Per-value weights, for weighted aggregates over a buffer.
+package types
+
+// PushWeighted is Push, also recording a weight for the value. Values pushed any other way
+// have a weight of 1.
+func (b *TypedBuffer) PushWeighted(value interface{}, weight float64) interface{} {
+	b.checkKind(value)
+	b.lockBuffer()
+	if b.weights == nil && weight != 1.0 {
+		b.weights = make([]float64, b.capacity)
+		for i := range b.weights {
+			b.weights[i] = 1.0
+		}
+	}
+	result, stream := b.pushWeightedLocked(value, weight)
+	b.unlockBuffer()
+	if stream != nil {
+		stream()
+	}
+	return result
+}
+
+// WeightedSum returns the sum of each float64 value in the buffer times its weight.
+func (b *TypedBuffer) WeightedSum() float64 {
+	sum, _ := b.weightedTotals()
+	return sum
+}
+
+// WeightedMean returns the mean of the float64 values in the buffer, weighted by their
+// pushed weights. Zero is returned if empty, or if the weights sum to zero.
+func (b *TypedBuffer) WeightedMean() float64 {
+	sum, totalWeight := b.weightedTotals()
+	if totalWeight == 0 {
+		return 0.0
+	}
+	return sum / totalWeight
+}
+
+// weightedTotals returns the weighted sum of the float64 values, and the sum of their weights.
+func (b *TypedBuffer) weightedTotals() (float64, float64) {
+	b.lockBuffer()
+	defer b.unlockBuffer()
+	sum, totalWeight := 0.0, 0.0
+	for i := 0; i < b.size; i++ {
+		index := b.physicalIndex(i)
+		f, ok := b.values[index].(float64)
+		if !ok || (b.skipInvalid && isInvalid(f)) {
+			continue
+		}
+		weight := 1.0
+		if b.weights != nil {
+			weight = b.weights[index]
+		}
+		sum += weight * f
+		totalWeight += weight
+	}
+	return sum, totalWeight
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWeightedAggregates(t *testing.T) {
+	b := NewTypedBuffer(4)
+	if actual := b.WeightedMean(); actual != 0 {
+		t.Errorf("Expected 0 for an empty buffer, got %f", actual)
+	}
+
+	b.Push(1.0)
+	b.PushWeighted(2.0, 3.0)
+	b.PushWeighted("skipped", 10.0)
+	b.PushWeighted(4.0, 0.5)
+
+	// 1*1 + 2*3 + 4*0.5
+	if actual := b.WeightedSum(); actual != 9.0 {
+		t.Errorf("Expected weighted sum 9, got %f", actual)
+	}
+	if actual := b.WeightedMean(); math.Abs(actual-9.0/4.5) > 1e-12 {
+		t.Errorf("Expected weighted mean %f, got %f", 9.0/4.5, actual)
+	}
+}
+
+func TestWeightedDefaultsToOne(t *testing.T) {
+	b := NewTypedBuffer(3)
+	for _, v := range []float64{1, 2, 6} {
+		b.Push(v)
+	}
+	if actual := b.WeightedMean(); actual != 3.0 {
+		t.Errorf("Expected the plain mean 3, got %f", actual)
+	}
+}
+
+func TestWeightedLockstepAfterWrap(t *testing.T) {
+	b := NewTypedBuffer(3)
+	// Each value's weight is its own value, so the weighted sum is the sum of squares.
+	for i := 1; i <= 7; i++ {
+		b.PushWeighted(float64(i), float64(i))
+	}
+	if actual := b.WeightedSum(); actual != 25+36+49 {
+		t.Errorf("Expected weighted sum %d, got %f", 25+36+49, actual)
+	}
+
+	// Unweighted pushes overwrite the old weights with 1.
+	b.Push(10.0)
+	if actual := b.WeightedSum(); actual != 36+49+10 {
+		t.Errorf("Expected weighted sum %d, got %f", 36+49+10, actual)
+	}
+
+	b.Compact()
+	if actual := b.WeightedSum(); actual != 36+49+10 {
+		t.Errorf("Expected Compact to keep weights aligned, got %f", actual)
+	}
+	b.PushWeighted(2.0, 2.0)
+	if actual := b.WeightedSum(); actual != 49+10+4 {
+		t.Errorf("Expected weighted sum %d after Compact, got %f", 49+10+4, actual)
+	}
+}